@@ -0,0 +1,157 @@
+package aql
+
+import (
+	"sort"
+	"strings"
+)
+
+// Function is a built-in AQL function.
+type Function struct {
+	Name string
+	// Signature names the parameters, e.g. `SUBSTRING(value, offset, length)`
+	Signature string
+}
+
+// functionSignatures lists the built-in functions documented for ArangoDB.
+// Functions a server knows but that are missing here are still completed,
+// just without a signature.
+var functionSignatures = []string{
+	// Type checks and casts
+	"TO_BOOL(value)", "TO_NUMBER(value)", "TO_STRING(value)", "TO_ARRAY(value)",
+	"TO_LIST(value)", "TO_CHAR(codepoint)", "TO_HEX(value)", "TO_BASE64(value)",
+	"IS_NULL(value)", "IS_BOOL(value)", "IS_NUMBER(value)", "IS_STRING(value)",
+	"IS_ARRAY(value)", "IS_LIST(value)", "IS_OBJECT(value)", "IS_DOCUMENT(value)",
+	"IS_DATESTRING(value)", "IS_IPV4(value)", "IS_KEY(value)", "TYPENAME(value)",
+
+	// Strings
+	"CHAR_LENGTH(value)", "CONCAT(value1, value2, ... valueN)",
+	"CONCAT_SEPARATOR(separator, value1, value2, ... valueN)",
+	"CONTAINS(text, search, returnIndex)", "CRC32(text)", "ENCODE_URI_COMPONENT(value)",
+	"FIND_FIRST(text, search, start, end)", "FIND_LAST(text, search, start, end)",
+	"FNV64(text)", "IPV4_FROM_NUMBER(numericAddress)", "IPV4_TO_NUMBER(stringAddress)",
+	"JSON_PARSE(text)", "JSON_STRINGIFY(value)", "LEFT(value, n)", "LENGTH(value)",
+	"LEVENSHTEIN_DISTANCE(value1, value2)", "LIKE(text, search, caseInsensitive)",
+	"LOWER(value)", "LTRIM(value, chars)", "MD5(text)",
+	"NGRAM_POSITIONAL_SIMILARITY(input, target, ngramSize)",
+	"NGRAM_SIMILARITY(input, target, ngramSize)", "RANDOM_TOKEN(length)",
+	"REGEX_MATCHES(text, regex, caseInsensitive)",
+	"REGEX_SPLIT(text, splitExpression, caseInsensitive, limit)",
+	"REGEX_TEST(text, search, caseInsensitive)",
+	"REGEX_REPLACE(text, search, replacement, caseInsensitive)",
+	"REVERSE(value)", "RIGHT(value, n)", "RTRIM(value, chars)", "SHA1(text)",
+	"SHA256(text)", "SHA512(text)", "SOUNDEX(value)", "SPLIT(value, separator, limit)",
+	"STARTS_WITH(text, prefix)", "SUBSTITUTE(value, search, replace, limit)",
+	"SUBSTRING(value, offset, length)", "SUBSTRING_BYTES(value, offset, length)",
+	"TOKENS(input, analyzer)", "TRIM(value, type)", "UPPER(value)", "UUID()",
+
+	// Numbers
+	"ABS(value)", "ACOS(value)", "ASIN(value)", "ATAN(value)", "ATAN2(y, x)",
+	"AVERAGE(numArray)", "AVG(numArray)", "CEIL(value)", "COS(value)",
+	"COSINE_SIMILARITY(x, y)", "DECAY_EXP(value, origin, scale, offset, decay)",
+	"DECAY_GAUSS(value, origin, scale, offset, decay)",
+	"DECAY_LINEAR(value, origin, scale, offset, decay)", "DEGREES(rad)", "EXP(value)",
+	"EXP2(value)", "FLOOR(value)", "L1_DISTANCE(x, y)", "L2_DISTANCE(x, y)",
+	"LOG(value)", "LOG2(value)", "LOG10(value)", "MAX(anyArray)", "MEDIAN(numArray)",
+	"MIN(anyArray)", "PERCENTILE(numArray, n, method)", "PI()", "POW(base, exp)",
+	"PRODUCT(numArray)", "RADIANS(deg)", "RAND()", "RANGE(start, stop, step)",
+	"ROUND(value)", "SIN(value)", "SQRT(value)", "STDDEV_POPULATION(numArray)",
+	"STDDEV_SAMPLE(numArray)", "STDDEV(numArray)", "SUM(numArray)", "TAN(value)",
+	"VARIANCE_POPULATION(numArray)", "VARIANCE_SAMPLE(numArray)", "VARIANCE(numArray)",
+
+	// Bits
+	"BIT_AND(numbersArray)", "BIT_CONSTRUCT(positionsArray)", "BIT_DECONSTRUCT(number)",
+	"BIT_FROM_STRING(bitstring)", "BIT_NEGATE(number, bits)", "BIT_OR(numbersArray)",
+	"BIT_POPCOUNT(number)", "BIT_SHIFT_LEFT(number, shift, bits)",
+	"BIT_SHIFT_RIGHT(number, shift, bits)", "BIT_TEST(number, index)",
+	"BIT_TO_STRING(number, bits)", "BIT_XOR(numbersArray)",
+
+	// Dates
+	"DATE_NOW()", "DATE_ISO8601(date)", "DATE_TIMESTAMP(date)", "DATE_DAYOFWEEK(date)",
+	"DATE_YEAR(date)", "DATE_MONTH(date)", "DATE_DAY(date)", "DATE_HOUR(date)",
+	"DATE_MINUTE(date)", "DATE_SECOND(date)", "DATE_MILLISECOND(date)",
+	"DATE_DAYOFYEAR(date)", "DATE_ISOWEEK(date)", "DATE_ISOWEEKYEAR(date)",
+	"DATE_LEAPYEAR(date)", "DATE_QUARTER(date)", "DATE_DAYS_IN_MONTH(date)",
+	"DATE_TRUNC(date, unit)", "DATE_ROUND(date, amount, unit)", "DATE_FORMAT(date, format)",
+	"DATE_ADD(date, amount, unit)", "DATE_SUBTRACT(date, amount, unit)",
+	"DATE_DIFF(date1, date2, unit, asFloat)",
+	"DATE_COMPARE(date1, date2, unitRangeStart, unitRangeEnd)",
+	"DATE_UTCTOLOCAL(date, timezone, zoneinfo)", "DATE_LOCALTOUTC(date, timezone, zoneinfo)",
+	"DATE_TIMEZONE()", "DATE_TIMEZONES(timezone)",
+
+	// Arrays
+	"APPEND(anyArray, values, unique)", "CONTAINS_ARRAY(anyArray, search, returnIndex)",
+	"COUNT(anyArray)", "COUNT_DISTINCT(anyArray)", "COUNT_UNIQUE(anyArray)",
+	"FIRST(anyArray)", "FLATTEN(anyArray, depth)", "INTERLEAVE(array1, array2, ... arrayN)",
+	"INTERSECTION(array1, array2, ... arrayN)", "JACCARD(array1, array2)", "LAST(anyArray)",
+	"MINUS(array1, array2, ... arrayN)", "NTH(anyArray, position)",
+	"OUTERSECTION(array1, array2, ... arrayN)", "POP(anyArray)",
+	"POSITION(anyArray, search, returnIndex)", "PUSH(anyArray, value, unique)",
+	"REMOVE_NTH(anyArray, position)",
+	"REPLACE_NTH(anyArray, position, replaceValue, defaultPaddingValue)",
+	"REMOVE_VALUE(anyArray, value, limit)", "REMOVE_VALUES(anyArray, values)",
+	"SHIFT(anyArray)", "SLICE(anyArray, start, length)", "SORTED(anyArray)",
+	"SORTED_UNIQUE(anyArray)", "UNION(array1, array2, ... arrayN)",
+	"UNION_DISTINCT(array1, array2, ... arrayN)", "UNIQUE(anyArray)",
+	"UNSHIFT(anyArray, value, unique)",
+
+	// Documents and objects
+	"ATTRIBUTES(document, removeSystemAttrs, sort)", "ENTRIES(document)",
+	"HAS(document, attributeName)", "IS_SAME_COLLECTION(collectionName, documentIdentifier)",
+	"KEEP(document, attributeName1, attributeName2, ... attributeNameN)",
+	"KEEP_RECURSIVE(document, attributeName1, attributeName2, ... attributeNameN)",
+	"KEYS(document, removeSystemAttrs, sort)", "MATCHES(document, examples, returnIndex)",
+	"MERGE(document1, document2, ... documentN)",
+	"MERGE_RECURSIVE(document1, document2, ... documentN)",
+	"PARSE_IDENTIFIER(documentIdentifier)", "PARSE_COLLECTION(documentIdentifier)",
+	"PARSE_KEY(documentIdentifier)", "TRANSLATE(value, lookupDocument, defaultValue)",
+	"UNSET(document, attributeName1, attributeName2, ... attributeNameN)",
+	"UNSET_RECURSIVE(document, attributeName1, attributeName2, ... attributeNameN)",
+	"VALUE(document, path)", "VALUES(document, removeSystemAttrs)", "ZIP(keys, values)",
+
+	// Geo
+	"DISTANCE(latitude1, longitude1, latitude2, longitude2)",
+	"GEO_CONTAINS(geoJsonA, geoJsonB)", "GEO_DISTANCE(geoJsonA, geoJsonB, ellipsoid)",
+	"GEO_AREA(geoJson, ellipsoid)", "GEO_EQUALS(geoJsonA, geoJsonB)",
+	"GEO_INTERSECTS(geoJsonA, geoJsonB)",
+	"GEO_IN_RANGE(geoJsonA, geoJsonB, low, high, includeLow, includeHigh)",
+	"IS_IN_POLYGON(polygon, latitude, longitude)", "GEO_LINESTRING(points)",
+	"GEO_MULTILINESTRING(points)", "GEO_MULTIPOINT(points)", "GEO_POINT(longitude, latitude)",
+	"GEO_POLYGON(points)", "GEO_MULTIPOLYGON(polygons)",
+
+	// ArangoSearch
+	"ANALYZER(expr, analyzer)", "BOOST(expr, boost)", "EXISTS(path, type)",
+	"IN_RANGE(path, low, high, includeLow, includeHigh)",
+	"MIN_MATCH(expr1, expr2, ... exprN, minMatchCount)",
+	"NGRAM_MATCH(path, target, threshold, analyzer)", "PHRASE(path, phrasePart, analyzer)",
+	"LEVENSHTEIN_MATCH(path, target, distance, transpositions, maxTerms, prefix)",
+	"BM25(doc, k, b)", "TFIDF(doc, normalize)", "OFFSET_INFO(doc, paths)",
+	"FULLTEXT(coll, attribute, query, limit)",
+
+	// Miscellaneous
+	"APPLY(functionName, arguments)", "ASSERT(expr, message)",
+	"CALL(functionName, arg1, arg2, ... argN)", "CHECK_DOCUMENT(document)",
+	"COLLECTION_COUNT(coll)", "COLLECTIONS()", "CURRENT_DATABASE()", "CURRENT_USER()",
+	"DECODE_REV(revision)", "DOCUMENT(collection, id)", "FAIL(reason)", "HASH(value)",
+	"NOOPT(value)", "PASSTHRU(value)", "SCHEMA_GET(collection)",
+	"SCHEMA_VALIDATE(document, schema)", "SHARD_ID(collection, shardKeys)",
+	"SLEEP(seconds)", "V8(expression)", "VERSION()", "WARN(expr, message)",
+}
+
+var functions = func() []Function {
+	seen := map[string]bool{}
+	var result []Function
+	for _, signature := range functionSignatures {
+		name := signature[:strings.IndexByte(signature, '(')]
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, Function{Name: name, Signature: signature})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}()
+
+// Functions returns the built-in AQL functions sorted by name.
+func Functions() []Function {
+	return functions
+}
@@ -0,0 +1,275 @@
+// Package aql contains a small tokenizer for the ArangoDB Query Language and
+// helpers built on top of it, such as statement splitting.
+package aql
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind classifies a token produced by Tokenize.
+type TokenKind int
+
+const (
+	Whitespace TokenKind = iota
+	Comment
+	String
+	Number
+	Keyword
+	Identifier
+	QuotedIdentifier
+	BindParam
+	Operator
+	Semicolon
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case Whitespace:
+		return "whitespace"
+	case Comment:
+		return "comment"
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case Keyword:
+		return "keyword"
+	case Identifier:
+		return "identifier"
+	case QuotedIdentifier:
+		return "quoted identifier"
+	case BindParam:
+		return "bind parameter"
+	case Operator:
+		return "operator"
+	case Semicolon:
+		return "semicolon"
+	}
+	return "unknown"
+}
+
+// Token is a single lexical element. Text is the exact source text, so
+// concatenating the text of all tokens reproduces the input.
+type Token struct {
+	Kind TokenKind
+	Text string
+	// Offset is the byte offset of the token in the input
+	Offset int
+	// Unterminated is set for strings, quoted identifiers and block comments
+	// that reach the end of the input without being closed.
+	Unterminated bool
+}
+
+// Significant reports whether the token carries meaning for the parser, that
+// is whether it is neither whitespace nor a comment.
+func (t Token) Significant() bool {
+	return t.Kind != Whitespace && t.Kind != Comment
+}
+
+// Is reports whether the token is the given keyword, compared case-insensitively.
+func (t Token) Is(keyword string) bool {
+	return t.Kind == Keyword && strings.EqualFold(t.Text, keyword)
+}
+
+// keywords holds the reserved words of AQL in upper case.
+var keywords = map[string]bool{
+	"AGGREGATE": true, "ALL": true, "ALL_SHORTEST_PATHS": true, "AND": true,
+	"ANY": true, "ASC": true, "COLLECT": true, "DESC": true, "DISTINCT": true,
+	"FALSE": true, "FILTER": true, "FOR": true, "GRAPH": true, "IN": true,
+	"INBOUND": true, "INSERT": true, "INTO": true, "K_PATHS": true,
+	"K_SHORTEST_PATHS": true, "LET": true, "LIKE": true, "LIMIT": true,
+	"NONE": true, "NOT": true, "NULL": true, "OR": true, "OUTBOUND": true,
+	"REMOVE": true, "REPLACE": true, "RETURN": true, "SHORTEST_PATH": true,
+	"SORT": true, "TRUE": true, "UPDATE": true, "UPSERT": true, "WINDOW": true,
+	"WITH": true,
+}
+
+// IsKeyword reports whether word is a reserved AQL keyword.
+func IsKeyword(word string) bool {
+	return keywords[strings.ToUpper(word)]
+}
+
+// Keywords returns the reserved AQL keywords in upper case, sorted.
+func Keywords() []string {
+	result := make([]string, 0, len(keywords))
+	for keyword := range keywords {
+		result = append(result, keyword)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// forwardTick is the alternative identifier quote character ´.
+const forwardTick = '´'
+
+// Tokenize splits input into tokens. It never fails: unknown characters are
+// returned as operator tokens and unclosed constructs are marked Unterminated.
+func Tokenize(input string) []Token {
+	var tokens []Token
+	pos := 0
+
+	for pos < len(input) {
+		start := pos
+		r, size := utf8.DecodeRuneInString(input[pos:])
+		token := Token{Offset: start}
+
+		switch {
+		case unicode.IsSpace(r):
+			token.Kind = Whitespace
+			pos = scanWhile(input, pos, unicode.IsSpace)
+
+		case strings.HasPrefix(input[pos:], "//"):
+			token.Kind = Comment
+			if end := strings.IndexByte(input[pos:], '\n'); end >= 0 {
+				pos += end
+			} else {
+				pos = len(input)
+			}
+
+		case strings.HasPrefix(input[pos:], "/*"):
+			token.Kind = Comment
+			if end := strings.Index(input[pos+2:], "*/"); end >= 0 {
+				pos += 2 + end + 2
+			} else {
+				pos = len(input)
+				token.Unterminated = true
+			}
+
+		case r == '"' || r == '\'':
+			token.Kind = String
+			pos, token.Unterminated = scanQuoted(input, pos+size, r, true)
+
+		case r == '`' || r == forwardTick:
+			token.Kind = QuotedIdentifier
+			pos, token.Unterminated = scanQuoted(input, pos+size, r, false)
+
+		case r == '@':
+			token.Kind = BindParam
+			pos += size
+			if pos < len(input) && input[pos] == '@' {
+				pos++
+			}
+			pos = scanWhile(input, pos, isIdentRune)
+			if pos == start+1 || (pos == start+2 && input[start+1] == '@') {
+				token.Kind = Operator
+			}
+
+		case unicode.IsDigit(r) || (r == '.' && pos+1 < len(input) && isDigit(input[pos+1]) && !afterValue(tokens)):
+			token.Kind = Number
+			pos = scanNumber(input, pos)
+
+		case isIdentStart(r):
+			pos = scanWhile(input, pos, isIdentRune)
+			token.Kind = Identifier
+			if IsKeyword(input[start:pos]) {
+				token.Kind = Keyword
+			}
+
+		case r == ';':
+			token.Kind = Semicolon
+			pos += size
+
+		default:
+			token.Kind = Operator
+			pos += size
+			for _, op := range multiCharOperators {
+				if strings.HasPrefix(input[start:], op) {
+					pos = start + len(op)
+					break
+				}
+			}
+		}
+
+		token.Text = input[start:pos]
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+var multiCharOperators = []string{"==", "!=", "<=", ">=", "=~", "!~", "&&", "||", "..", "::", "?:"}
+
+func scanWhile(input string, pos int, accept func(rune) bool) int {
+	for pos < len(input) {
+		r, size := utf8.DecodeRuneInString(input[pos:])
+		if !accept(r) {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+// scanQuoted scans up to and including the closing quote. Strings allow
+// backslash escapes; quoted identifiers do not.
+func scanQuoted(input string, pos int, quote rune, escapes bool) (int, bool) {
+	for pos < len(input) {
+		r, size := utf8.DecodeRuneInString(input[pos:])
+		pos += size
+		if escapes && r == '\\' {
+			_, size = utf8.DecodeRuneInString(input[pos:])
+			pos += size
+			continue
+		}
+		if r == quote {
+			return pos, false
+		}
+	}
+	return len(input), true
+}
+
+func scanNumber(input string, pos int) int {
+	if strings.HasPrefix(input[pos:], "0x") || strings.HasPrefix(input[pos:], "0X") ||
+		strings.HasPrefix(input[pos:], "0b") || strings.HasPrefix(input[pos:], "0B") {
+		return scanWhile(input, pos+2, isIdentRune)
+	}
+
+	pos = scanWhile(input, pos, unicode.IsDigit)
+	// A single dot starts the fraction, two dots are the range operator
+	if pos+1 < len(input) && input[pos] == '.' && isDigit(input[pos+1]) {
+		pos = scanWhile(input, pos+1, unicode.IsDigit)
+	}
+	if pos < len(input) && (input[pos] == 'e' || input[pos] == 'E') {
+		next := pos + 1
+		if next < len(input) && (input[next] == '+' || input[next] == '-') {
+			next++
+		}
+		if next < len(input) && isDigit(input[next]) {
+			pos = scanWhile(input, next, unicode.IsDigit)
+		}
+	}
+	return pos
+}
+
+// afterValue reports whether the last significant token ends a value, in which
+// case a following dot is attribute access rather than the start of a number.
+func afterValue(tokens []Token) bool {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if !tokens[i].Significant() {
+			continue
+		}
+		switch tokens[i].Kind {
+		case Identifier, QuotedIdentifier, BindParam, Number, String:
+			return true
+		case Operator:
+			return tokens[i].Text == ")" || tokens[i].Text == "]"
+		}
+		return false
+	}
+	return false
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
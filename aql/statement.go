@@ -0,0 +1,113 @@
+package aql
+
+import "strings"
+
+// statementKeywords are the keywords a complete AQL query can start with.
+var statementKeywords = []string{
+	"FOR", "LET", "RETURN", "INSERT", "UPDATE", "REPLACE", "REMOVE", "UPSERT", "WITH",
+}
+
+// Split splits input into statements separated by semicolons. Semicolons
+// inside strings, quoted identifiers and comments do not split. Statements
+// consisting only of whitespace and comments are dropped.
+func Split(input string) []string {
+	statements, rest := SplitComplete(input)
+	if rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// SplitComplete returns the statements of input that are terminated by a
+// semicolon, and the trimmed text after the last terminated statement.
+func SplitComplete(input string) ([]string, string) {
+	var statements []string
+	start := 0
+
+	for _, token := range Tokenize(input) {
+		if token.Kind != Semicolon {
+			continue
+		}
+		if statement := input[start:token.Offset]; !IsBlank(statement) {
+			statements = append(statements, strings.TrimSpace(statement))
+		}
+		start = token.Offset + len(token.Text)
+	}
+
+	rest := input[start:]
+	if IsBlank(rest) {
+		rest = ""
+	}
+	return statements, strings.TrimSpace(rest)
+}
+
+// StatementAt returns the trimmed statement of input that contains the byte
+// offset, without its semicolon. An offset in trailing blank text selects
+// the last statement.
+func StatementAt(input string, offset int) string {
+	start := 0
+	last := ""
+	for _, token := range Tokenize(input) {
+		if token.Kind != Semicolon {
+			continue
+		}
+		statement := input[start:token.Offset]
+		if offset <= token.Offset && !IsBlank(statement) {
+			return strings.TrimSpace(statement)
+		}
+		if !IsBlank(statement) {
+			last = statement
+		}
+		start = token.Offset + len(token.Text)
+	}
+	if rest := input[start:]; !IsBlank(rest) {
+		return strings.TrimSpace(rest)
+	}
+	return strings.TrimSpace(last)
+}
+
+// IsBlank reports whether input contains nothing but whitespace and comments.
+func IsBlank(input string) bool {
+	for _, token := range Tokenize(input) {
+		if token.Significant() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsExpression reports whether query is a bare expression such as
+// `LENGTH(users)` rather than a statement, judged by its first keyword.
+func IsExpression(query string) bool {
+	for _, token := range Tokenize(query) {
+		if !token.Significant() {
+			continue
+		}
+		for _, keyword := range statementKeywords {
+			if token.Is(keyword) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// BindParams returns the bind parameter keys used in query, in order of first
+// use and as they are sent to the server: "name" for @name and "@coll" for
+// @@coll.
+func BindParams(query string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, token := range Tokenize(query) {
+		if token.Kind != BindParam {
+			continue
+		}
+		name := token.Text[1:]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
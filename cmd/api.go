@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+
+	driver "github.com/arangodb/go-driver"
+)
+
+// apiRequest performs a request against an HTTP API of the current database
+// that the driver does not wrap. The response body is decoded into result
+// unless it is nil.
+func (s *ShellContext) apiRequest(ctx context.Context, method, apiPath string, body, result interface{}, expectedStatus ...int) error {
+	return s.apiRequestWithHeaders(ctx, method, apiPath, nil, body, result, expectedStatus...)
+}
+
+// apiRequestWithHeaders is apiRequest with extra request headers, which the
+// driver would otherwise derive from the context, such as the transaction.
+func (s *ShellContext) apiRequestWithHeaders(ctx context.Context, method, apiPath string, headers map[string]string, body, result interface{}, expectedStatus ...int) error {
+	conn := s.Client.Connection()
+	req, err := conn.NewRequest(method, path.Join("_db", url.PathEscape(s.CurrentDB), apiPath))
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		req.SetHeader(name, value)
+	}
+	if body != nil {
+		if _, err := req.SetBody(body); err != nil {
+			return err
+		}
+	}
+
+	var raw []byte
+	resp, err := conn.Do(driver.WithRawResponse(ctx, &raw), req)
+	if err != nil {
+		return err
+	}
+	if err := resp.CheckStatus(expectedStatus...); err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, result)
+}
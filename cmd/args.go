@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newShellFlagSet returns a flag set for parsing the arguments of a shell
+// command such as "/begin --write users". Errors are returned to the caller
+// instead of exiting the shell.
+func newShellFlagSet(command string) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(command, pflag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	fs.Usage = func() {
+		fmt.Printf("Usage of %s:\n%s", command, fs.FlagUsages())
+	}
+	return fs
+}
+
+// sessionFunc returns the session a command operates on. The CLI connects
+// using the connection flags, the shell passes its own session.
+type sessionFunc func() (*ShellContext, error)
+
+// session is the sessionFunc of commands run inside the shell.
+func (s *ShellContext) session() (*ShellContext, error) {
+	return s, nil
+}
+
+// runShellCommand executes a command tree that is shared with the CLI, such
+// as /collection, inside the shell.
+func (s *ShellContext) runShellCommand(c *cobra.Command, args []string) {
+	c.SetArgs(args)
+	c.SetOut(os.Stdout)
+	c.SetErr(os.Stdout)
+	c.SilenceErrors = true
+	c.SilenceUsage = true
+	c.CompletionOptions.DisableDefaultCmd = true
+	if err := c.Execute(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// confirm asks a yes/no question and defaults to no, also when stdin is not
+// interactive.
+func confirm(question string) bool {
+	answer, err := readLine(question + " [y/N] ")
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+func anyFlagChanged(c *cobra.Command, names ...string) bool {
+	for _, name := range names {
+		if c.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellFields splits a shell command line like strings.Fields, but keeps
+// inline JSON such as {"name": "alice"} together as one argument. Outside of
+// JSON, single or double quotes group words and are removed, as in a shell.
+func shellFields(input string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		started bool
+		depth   int
+		quote   rune
+		escaped bool
+	)
+	for _, r := range input {
+		switch {
+		case depth > 0 && quote != 0:
+			// Inside a JSON string
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+		case depth > 0 && r == '"':
+			quote = r
+		case r == '{' || r == '[':
+			depth++
+		case (r == '}' || r == ']') && depth > 0:
+			depth--
+		case r == '"' || r == '\'':
+			quote = r
+			started = true
+			continue
+		case unicode.IsSpace(r) && depth == 0:
+			if started {
+				fields = append(fields, current.String())
+				current.Reset()
+				started = false
+			}
+			continue
+		}
+		current.WriteRune(r)
+		started = true
+	}
+	if started {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
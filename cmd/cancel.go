@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// interruptibleQuery is the context of a single query that is cancelled when
+// the user presses Ctrl-C.
+type interruptibleQuery struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	signals     chan os.Signal
+	interrupted chan struct{}
+	started     time.Time
+}
+
+// runningQuery is an entry of the query tracking API.
+type runningQuery struct {
+	ID      string  `json:"id"`
+	Query   string  `json:"query"`
+	RunTime float64 `json:"runTime"`
+	State   string  `json:"state"`
+}
+
+// startInterruptible returns a query context derived from the session context
+// that is cancelled on SIGINT. stop must be called once the query is done.
+func (s *ShellContext) startInterruptible() *interruptibleQuery {
+	ctx, cancel := context.WithCancel(s.Context)
+	q := &interruptibleQuery{
+		ctx:         ctx,
+		cancel:      cancel,
+		signals:     make(chan os.Signal, 1),
+		interrupted: make(chan struct{}),
+		started:     time.Now(),
+	}
+
+	signal.Notify(q.signals, os.Interrupt)
+	go func() {
+		select {
+		case <-q.signals:
+			close(q.interrupted)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return q
+}
+
+// wasInterrupted reports whether the query was cancelled by Ctrl-C.
+func (q *interruptibleQuery) wasInterrupted() bool {
+	select {
+	case <-q.interrupted:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop stops listening for Ctrl-C and releases the context.
+func (q *interruptibleQuery) stop() {
+	signal.Stop(q.signals)
+	q.cancel()
+}
+
+// killQuery kills the server-side query with the given text that was started
+// by an interrupted request and returns a message for the user.
+func (s *ShellContext) killQuery(query string, started time.Time) string {
+	elapsed := time.Since(started).Round(100 * time.Millisecond)
+
+	// The request context is cancelled already, so use a fresh one
+	ctx, cancel := context.WithTimeout(s.Context, 10*time.Second)
+	defer cancel()
+
+	var current []runningQuery
+	if err := s.apiRequest(ctx, "GET", "_api/query/current", nil, &current, http.StatusOK); err != nil {
+		return fmt.Sprintf("Query cancelled after %v, but running queries could not be listed: %v", elapsed, err)
+	}
+	// Our query was sent after started, so it cannot have run longer than
+	// this. Queries with the same text that run longer belong to someone else.
+	window := time.Since(started).Seconds() + 0.1
+
+	var candidates []runningQuery
+	for _, running := range current {
+		if strings.TrimSpace(running.Query) == strings.TrimSpace(query) && running.RunTime <= window {
+			candidates = append(candidates, running)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Sprintf("Query cancelled after %v", elapsed)
+	}
+	if len(candidates) > 1 {
+		// Killing one of them could hit someone else's query
+		return fmt.Sprintf("Query cancelled after %v, but it was not killed on the server: %d running queries have the same text", elapsed, len(candidates))
+	}
+	target := candidates[0]
+
+	if err := s.apiRequest(ctx, "DELETE", "_api/query/"+target.ID, nil, nil, http.StatusOK); err != nil {
+		return fmt.Sprintf("Query cancelled after %v, but killing it on the server failed: %v", elapsed, err)
+	}
+	return fmt.Sprintf("Query killed after %v", elapsed)
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/cobra"
+)
+
+// newCollectionCmd builds the collection management commands. The same tree
+// backs `arango-cli collection` and the shell's /collection command.
+func newCollectionCmd(use string, session sessionFunc) *cobra.Command {
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Manage collections",
+	}
+	c.AddCommand(
+		newCollectionCreateCmd(session),
+		newCollectionDropCmd(session),
+		newCollectionTruncateCmd(session),
+		newCollectionRenameCmd(session),
+		newCollectionPropertiesCmd(session),
+		newCollectionCountCmd(session),
+		newCollectionFiguresCmd(session),
+	)
+	return c
+}
+
+func newCollectionCreateCmd(session sessionFunc) *cobra.Command {
+	var (
+		edge         bool
+		shards       int
+		replication  int
+		keyGenerator string
+		waitForSync  bool
+	)
+	c := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a document or edge collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+
+			options := &driver.CreateCollectionOptions{
+				NumberOfShards:    shards,
+				ReplicationFactor: replication,
+				WaitForSync:       waitForSync,
+			}
+			if edge {
+				options.Type = driver.CollectionTypeEdge
+			}
+			if keyGenerator != "" {
+				options.KeyOptions = &driver.CollectionKeyOptions{Type: driver.KeyGeneratorType(keyGenerator)}
+			}
+
+			col, err := s.DB.CreateCollection(s.Context, args[0], options)
+			if err != nil {
+				return err
+			}
+			kind := "document"
+			if edge {
+				kind = "edge"
+			}
+			fmt.Printf("Created %s collection '%s'\n", kind, col.Name())
+			return nil
+		},
+	}
+	c.Flags().BoolVar(&edge, "edge", false, "Create an edge collection")
+	c.Flags().IntVar(&shards, "shards", 0, "Number of shards (cluster only)")
+	c.Flags().IntVar(&replication, "replication", 0, "Replication factor (cluster only)")
+	c.Flags().StringVar(&keyGenerator, "key-generator", "", "Key generator: traditional, autoincrement, uuid or padded")
+	c.Flags().BoolVar(&waitForSync, "wait-for-sync", false, "Sync every write to disk before acknowledging it")
+	return c
+}
+
+func newCollectionDropCmd(session sessionFunc) *cobra.Command {
+	var yes bool
+	c := &cobra.Command{
+		Use:   "drop <name>",
+		Short: "Drop a collection and all of its documents",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+			if !yes && !confirmDestructive(s, col, "Drop") {
+				fmt.Println("Cancelled")
+				return nil
+			}
+			if err := col.Remove(s.Context); err != nil {
+				return err
+			}
+			fmt.Printf("Dropped collection '%s'\n", col.Name())
+			return nil
+		},
+	}
+	c.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	return c
+}
+
+func newCollectionTruncateCmd(session sessionFunc) *cobra.Command {
+	var yes bool
+	c := &cobra.Command{
+		Use:   "truncate <name>",
+		Short: "Remove all documents from a collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+			if !yes && !confirmDestructive(s, col, "Truncate") {
+				fmt.Println("Cancelled")
+				return nil
+			}
+			if err := col.Truncate(s.Context); err != nil {
+				return err
+			}
+			fmt.Printf("Truncated collection '%s'\n", col.Name())
+			return nil
+		},
+	}
+	c.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	return c
+}
+
+func newCollectionRenameCmd(session sessionFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <name> <new-name>",
+		Short: "Rename a collection",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+			if err := col.Rename(s.Context, args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("Renamed collection '%s' to '%s'\n", args[0], args[1])
+			return nil
+		},
+	}
+}
+
+func newCollectionPropertiesCmd(session sessionFunc) *cobra.Command {
+	var (
+		options    driver.SetCollectionPropertiesOptions
+		sync       bool
+		cache      bool
+		schemaFile string
+	)
+	c := &cobra.Command{
+		Use:   "properties <name>",
+		Short: "Show or change the properties of a collection",
+		Long: `Show the properties of a collection. When any of the flags is given, the
+corresponding properties are changed first.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+
+			if anyFlagChanged(cmd, "wait-for-sync", "cache-enabled", "replication", "write-concern", "schema") {
+				if cmd.Flags().Changed("wait-for-sync") {
+					options.WaitForSync = &sync
+				}
+				if cmd.Flags().Changed("cache-enabled") {
+					options.CacheEnabled = &cache
+				}
+				if schemaFile != "" {
+					data, err := os.ReadFile(schemaFile)
+					if err != nil {
+						return fmt.Errorf("failed to read schema file: %v", err)
+					}
+					options.Schema = &driver.CollectionSchemaOptions{}
+					if err := json.Unmarshal(data, options.Schema); err != nil {
+						return fmt.Errorf("schema file must contain {\"rule\": ..., \"level\": ..., \"message\": ...}: %v", err)
+					}
+				}
+				if err := col.SetProperties(s.Context, options); err != nil {
+					return err
+				}
+				fmt.Printf("Updated properties of '%s'\n", col.Name())
+			}
+
+			props, err := col.Properties(s.Context)
+			if err != nil {
+				return err
+			}
+			printCollectionProperties(props)
+			return nil
+		},
+	}
+	c.Flags().BoolVar(&sync, "wait-for-sync", false, "Sync every write to disk before acknowledging it")
+	c.Flags().BoolVar(&cache, "cache-enabled", false, "Enable the in-memory document cache")
+	c.Flags().IntVar(&options.ReplicationFactor, "replication", 0, "Replication factor (cluster only)")
+	c.Flags().IntVar(&options.WriteConcern, "write-concern", 0, "Write concern (cluster only)")
+	c.Flags().StringVar(&schemaFile, "schema", "", "JSON file with the schema rule, level and message")
+	return c
+}
+
+func newCollectionCountCmd(session sessionFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "count <name>",
+		Short: "Count the documents of a collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+			count, err := col.Count(s.transactionContext(s.Context))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s: %d documents\n", col.Name(), count)
+			return nil
+		},
+	}
+}
+
+// collectionFigures is the response of the collection figures API for the
+// RocksDB storage engine.
+type collectionFigures struct {
+	Count   int64 `json:"count"`
+	Figures struct {
+		DocumentsSize int64 `json:"documentsSize"`
+		CacheInUse    bool  `json:"cacheInUse"`
+		CacheSize     int64 `json:"cacheSize"`
+		CacheUsage    int64 `json:"cacheUsage"`
+		Indexes       struct {
+			Count int64 `json:"count"`
+			Size  int64 `json:"size"`
+		} `json:"indexes"`
+	} `json:"figures"`
+}
+
+func newCollectionFiguresCmd(session sessionFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "figures <name>",
+		Short: "Show document count and storage sizes of a collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+
+			var figures collectionFigures
+			if err := s.apiRequest(s.Context, "GET", "_api/collection/"+url.PathEscape(args[0])+"/figures", nil, &figures, http.StatusOK); err != nil {
+				return err
+			}
+			fmt.Printf("Figures of '%s':\n", args[0])
+			fmt.Printf("  Documents:      %d\n", figures.Count)
+			fmt.Printf("  Documents size: %s\n", formatBytes(int(figures.Figures.DocumentsSize)))
+			fmt.Printf("  Indexes:        %d (%s)\n", figures.Figures.Indexes.Count, formatBytes(int(figures.Figures.Indexes.Size)))
+			if figures.Figures.CacheInUse {
+				fmt.Printf("  Cache:          %s of %s in use\n", formatBytes(int(figures.Figures.CacheUsage)), formatBytes(int(figures.Figures.CacheSize)))
+			} else {
+				fmt.Printf("  Cache:          not in use\n")
+			}
+			return nil
+		},
+	}
+}
+
+// confirmDestructive asks before dropping or truncating a collection, showing
+// how many documents are affected.
+func confirmDestructive(s *ShellContext, col driver.Collection, action string) bool {
+	question := fmt.Sprintf("%s collection '%s'?", action, col.Name())
+	if count, err := col.Count(s.Context); err == nil {
+		question = fmt.Sprintf("%s collection '%s' with %d documents?", action, col.Name(), count)
+	}
+	return confirm(question)
+}
+
+func printCollectionProperties(props driver.CollectionProperties) {
+	kind := "document"
+	if props.Type == driver.CollectionTypeEdge {
+		kind = "edge"
+	}
+
+	fmt.Printf("Properties of '%s':\n", props.Name)
+	fmt.Printf("  ID:              %s\n", props.ID)
+	fmt.Printf("  Type:            %s\n", kind)
+	fmt.Printf("  System:          %t\n", props.IsSystem)
+	fmt.Printf("  Wait for sync:   %t\n", props.WaitForSync)
+	fmt.Printf("  Cache enabled:   %t\n", props.CacheEnabled)
+	fmt.Printf("  Key generator:   %s (user keys allowed: %t)\n", props.KeyOptions.Type, props.KeyOptions.AllowUserKeys)
+	if props.NumberOfShards > 0 {
+		fmt.Printf("  Shards:          %d (keys: %s)\n", props.NumberOfShards, strings.Join(props.ShardKeys, ", "))
+		fmt.Printf("  Replication:     %d (write concern %d)\n", props.ReplicationFactor, props.WriteConcern)
+	}
+	if props.Schema != nil {
+		rule, _ := json.Marshal(props.Schema.Rule)
+		fmt.Printf("  Schema:          level %s, rule %s\n", props.Schema.Level, rule)
+	}
+}
+
+var collectionCmd = newCollectionCmd("collection", connectFromFlags)
+
+func init() {
+	rootCmd.AddCommand(collectionCmd)
+
+	// Keep stdout clean for scripts, so no banner here
+	collectionCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+	addConnectionFlags(collectionCmd.PersistentFlags())
+}
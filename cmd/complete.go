@@ -0,0 +1,503 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/thakurankit7/arango-cli/aql"
+)
+
+// completionWordSeparator ends the word that is replaced by a completion.
+// Slashes, dashes and colons are part of words so that commands, profile
+// names and user-defined function names are completed as a whole.
+const completionWordSeparator = " \t\n()[]{},.=<>!+*%?;"
+
+// attributeSampleSize is the number of documents attribute names are
+// collected from.
+const attributeSampleSize = 50
+
+// completionCache holds the schema information used for completion. Entries
+// are loaded in the background the first time they are needed, so typing
+// never waits for the server, and are replaced as a whole once loaded.
+type completionCache struct {
+	mu sync.Mutex
+	// generation is increased by refresh, loads started before are dropped
+	generation int
+	databases  []string
+	catalogs   map[string]*schemaCatalog
+	// attributes maps database/collection to dotted attribute paths
+	attributes map[string][]string
+}
+
+// schemaCatalog lists the objects of one database.
+type schemaCatalog struct {
+	collections []string
+	views       []string
+	graphs      []string
+	// functions are the user-defined AQL functions and built-in functions
+	// the server knows but functionSuggestions lacks
+	functions []prompt.Suggest
+}
+
+func newCompletionCache() *completionCache {
+	return &completionCache{
+		catalogs:   map[string]*schemaCatalog{},
+		attributes: map[string][]string{},
+	}
+}
+
+// refresh drops everything loaded so far. It is called after commands that
+// create, drop or rename collections.
+func (c *completionCache) refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.databases = nil
+	c.catalogs = map[string]*schemaCatalog{}
+	c.attributes = map[string][]string{}
+}
+
+// store runs fn under the lock unless the cache was refreshed since
+// generation.
+func (c *completionCache) store(generation int, fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		fn()
+	}
+}
+
+// catalog returns the schema of the current database, or an empty catalog
+// while it is loading.
+func (s *ShellContext) catalog() *schemaCatalog {
+	c := s.Completion
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if catalog, ok := c.catalogs[s.CurrentDB]; ok {
+		return catalog
+	}
+	c.catalogs[s.CurrentDB] = &schemaCatalog{}
+
+	session, generation := *s, c.generation
+	go func() {
+		catalog := session.loadCatalog()
+		c.store(generation, func() { c.catalogs[session.CurrentDB] = catalog })
+	}()
+	return c.catalogs[s.CurrentDB]
+}
+
+// loadCatalog reads the schema of the current database. Whatever cannot be
+// read, for example for lack of permissions, is left out.
+func (s *ShellContext) loadCatalog() *schemaCatalog {
+	ctx, cancel := context.WithTimeout(s.Context, 10*time.Second)
+	defer cancel()
+	catalog := &schemaCatalog{}
+
+	if cols, err := s.DB.Collections(ctx); err == nil {
+		for _, col := range cols {
+			if !strings.HasPrefix(col.Name(), "_") {
+				catalog.collections = append(catalog.collections, col.Name())
+			}
+		}
+	}
+	if views, err := s.DB.Views(ctx); err == nil {
+		for _, view := range views {
+			catalog.views = append(catalog.views, view.Name())
+		}
+	}
+	if graphs, err := s.DB.Graphs(ctx); err == nil {
+		for _, graph := range graphs {
+			catalog.graphs = append(catalog.graphs, graph.Name())
+		}
+	}
+	sort.Strings(catalog.collections)
+	sort.Strings(catalog.views)
+	sort.Strings(catalog.graphs)
+
+	known := map[string]bool{}
+	for _, function := range aql.Functions() {
+		known[function.Name] = true
+	}
+	var builtins struct {
+		Functions []struct {
+			Name string `json:"name"`
+		} `json:"functions"`
+	}
+	if err := s.apiRequest(ctx, "GET", "_api/aql-builtin", nil, &builtins); err == nil {
+		for _, function := range builtins.Functions {
+			if !known[function.Name] && !strings.HasPrefix(function.Name, "_") {
+				known[function.Name] = true
+				catalog.functions = append(catalog.functions, prompt.Suggest{Text: function.Name, Description: "built-in function"})
+			}
+		}
+	}
+	var udfs struct {
+		Result []struct {
+			Name string `json:"name"`
+			Code string `json:"code"`
+		} `json:"result"`
+	}
+	if err := s.apiRequest(ctx, "GET", "_api/aqlfunction", nil, &udfs); err == nil {
+		for _, function := range udfs.Result {
+			catalog.functions = append(catalog.functions, prompt.Suggest{
+				Text:        function.Name,
+				Description: function.Name + udfParameters(function.Code) + " (user-defined)",
+			})
+		}
+	}
+	return catalog
+}
+
+// udfParameters returns the parameter list of the JavaScript code of a
+// user-defined function, such as "(celsius)".
+func udfParameters(code string) string {
+	start := strings.IndexByte(code, '(')
+	end := strings.IndexByte(code, ')')
+	if start < 0 || end < start {
+		return "()"
+	}
+	return "(" + strings.Join(strings.Fields(code[start+1:end]), " ") + ")"
+}
+
+// collectionAttributes returns the attribute paths seen in a sample of the
+// documents of collection, or nothing while the sample is loading.
+func (s *ShellContext) collectionAttributes(collection string) []string {
+	c := s.Completion
+	key := s.CurrentDB + "/" + collection
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if attributes, ok := c.attributes[key]; ok {
+		return attributes
+	}
+	c.attributes[key] = nil
+
+	session, generation := *s, c.generation
+	go func() {
+		attributes := session.sampleAttributes(collection)
+		c.store(generation, func() { c.attributes[key] = attributes })
+	}()
+	return nil
+}
+
+func (s *ShellContext) sampleAttributes(collection string) []string {
+	ctx, cancel := context.WithTimeout(s.Context, 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.DB.Query(ctx, "FOR doc IN @@collection LIMIT @limit RETURN doc",
+		map[string]interface{}{"@collection": collection, "limit": attributeSampleSize})
+	if err != nil {
+		return nil
+	}
+	defer cursor.Close()
+
+	seen := map[string]bool{}
+	for {
+		var doc map[string]interface{}
+		if _, err := cursor.ReadDocument(ctx, &doc); err != nil {
+			break
+		}
+		collectPaths(doc, "", 3, seen)
+	}
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// collectPaths adds the dotted paths of the attributes of doc to seen, down
+// to depth levels.
+func collectPaths(doc map[string]interface{}, prefix string, depth int, seen map[string]bool) {
+	for name, value := range doc {
+		seen[prefix+name] = true
+		if object, ok := value.(map[string]interface{}); ok && depth > 1 {
+			collectPaths(object, prefix+name+".", depth-1, seen)
+		}
+	}
+}
+
+// databaseNames returns the databases the user can access, or nothing while
+// they are loading.
+func (s *ShellContext) databaseNames() []string {
+	c := s.Completion
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.databases != nil {
+		return c.databases
+	}
+	c.databases = []string{}
+
+	session, generation := *s, c.generation
+	go func() {
+		ctx, cancel := context.WithTimeout(session.Context, 10*time.Second)
+		defer cancel()
+		dbs, err := session.Client.AccessibleDatabases(ctx)
+		if err != nil {
+			return
+		}
+		names := make([]string, 0, len(dbs))
+		for _, db := range dbs {
+			names = append(names, db.Name())
+		}
+		sort.Strings(names)
+		c.store(generation, func() { c.databases = names })
+	}()
+	return c.databases
+}
+
+// completer suggests completions for the word before the cursor, depending
+// on where it is: shell commands and their arguments, or the parts of an AQL
+// statement.
+func (s *ShellContext) completer(d prompt.Document) []prompt.Suggest {
+	word := d.GetWordBeforeCursorUntilSeparator(completionWordSeparator)
+	before := d.TextBeforeCursor()
+	before = before[:len(before)-len(word)]
+
+	if !isMultilineMode && strings.HasPrefix(strings.TrimSpace(before+word), "/") {
+		return s.completeCommand(before, word)
+	}
+	if isMultilineMode {
+		// Earlier lines of the statement bind the variables
+		before = buffer.String() + before
+	}
+	if aql.IsBlank(before) && !isMultilineMode {
+		// At the start of the input shell commands are suggested as well
+		if word == "" {
+			return shellSuggestions
+		}
+		return mergeSuggestions(prompt.FilterHasPrefix(shellSuggestions, word, true), s.completeAQL(before, word))
+	}
+	return s.completeAQL(before, word)
+}
+
+// mergeSuggestions appends the suggestions of more to suggestions that are
+// not in it yet.
+func mergeSuggestions(suggestions, more []prompt.Suggest) []prompt.Suggest {
+	seen := map[string]bool{}
+	for _, suggestion := range suggestions {
+		seen[suggestion.Text] = true
+	}
+	for _, suggestion := range more {
+		if !seen[suggestion.Text] {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
+
+// collectionCommands take a collection as their first argument.
+var collectionCommands = map[string]bool{
+	"/indexes": true, "/get": true, "/insert": true, "/update": true,
+	"/replace": true, "/delete": true, "/remove": true, "/edit": true,
+}
+
+func (s *ShellContext) completeCommand(before, word string) []prompt.Suggest {
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return prompt.FilterHasPrefix(shellSuggestions, word, true)
+	}
+
+	var names []string
+	description := ""
+	switch command := strings.ToLower(fields[0]); {
+	case command == "/explain" || command == "/profile":
+		return s.completeAQL(strings.TrimSpace(before)[len(command):], word)
+	case fields[len(fields)-1] == "--collection":
+		names, description = s.catalog().collections, "collection"
+	case command == "/use" && len(fields) == 1:
+		names, description = s.databaseNames(), "database"
+	case command == "/switch" && len(fields) == 1 && s.ConfigManager != nil:
+		names, description = s.ConfigManager.ListDatabases(), "configuration"
+		sort.Strings(names)
+	case collectionCommands[command] && len(fields) == 1,
+		command == "/collection" && len(fields) == 2 && fields[1] != "create",
+		command == "/index" && len(fields) == 2:
+		names, description = s.catalog().collections, "collection"
+	}
+	return prompt.FilterHasPrefix(namedSuggestions(names, description), word, true)
+}
+
+func (s *ShellContext) completeAQL(before, word string) []prompt.Suggest {
+	if strings.HasPrefix(word, "@") {
+		return prompt.FilterHasPrefix(s.bindParamSuggestions(), word, true)
+	}
+
+	var tokens []aql.Token
+	for _, token := range aql.Tokenize(before) {
+		if token.Significant() {
+			tokens = append(tokens, token)
+		}
+	}
+	// Completing inside a string or comment would only get in the way,
+	// except for graph names which are strings
+	if n := len(tokens); n > 0 && tokens[n-1].Unterminated {
+		return nil
+	}
+	if strings.HasPrefix(word, `"`) || strings.HasPrefix(word, "'") {
+		if n := len(tokens); n > 0 && tokens[n-1].Is("GRAPH") {
+			return prompt.FilterHasPrefix(s.graphSuggestions(word[:1]), word, true)
+		}
+		return nil
+	}
+
+	catalog := s.catalog()
+	if n := len(tokens); n > 0 {
+		last := tokens[n-1]
+		switch {
+		case last.Kind == aql.Operator && last.Text == ".":
+			return prompt.FilterHasPrefix(s.attributeSuggestions(tokens), word, true)
+		case last.Is("IN") || last.Is("INTO") || last.Is("WITH"):
+			suggestions := namedSuggestions(catalog.collections, "collection")
+			suggestions = append(suggestions, namedSuggestions(catalog.views, "view")...)
+			return prompt.FilterHasPrefix(suggestions, word, true)
+		case last.Is("GRAPH"):
+			return prompt.FilterHasPrefix(s.graphSuggestions(`"`), word, true)
+		}
+	}
+	if word == "" {
+		return nil
+	}
+
+	var suggestions []prompt.Suggest
+	suggestions = append(suggestions, namedSuggestions(boundVariables(tokens), "variable")...)
+	suggestions = append(suggestions, namedSuggestions(catalog.collections, "collection")...)
+	suggestions = append(suggestions, keywordSuggestions...)
+	suggestions = append(suggestions, functionSuggestions...)
+	suggestions = append(suggestions, catalog.functions...)
+	return prompt.FilterHasPrefix(suggestions, word, true)
+}
+
+// attributeSuggestions completes the attribute path ending in tokens, such
+// as `doc.address.`, with the attributes sampled from the collection the
+// variable iterates over.
+func (s *ShellContext) attributeSuggestions(tokens []aql.Token) []prompt.Suggest {
+	// Walk back over name.name. to the variable
+	var path []string
+	i := len(tokens) - 1
+	for i > 0 && tokens[i].Kind == aql.Operator && tokens[i].Text == "." &&
+		(tokens[i-1].Kind == aql.Identifier || tokens[i-1].Kind == aql.QuotedIdentifier) {
+		path = append([]string{strings.Trim(tokens[i-1].Text, "`´")}, path...)
+		i -= 2
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	collection := s.boundCollection(tokens, path[0])
+	if collection == "" {
+		return nil
+	}
+
+	prefix := strings.Join(path[1:], ".")
+	if prefix != "" {
+		prefix += "."
+	}
+	seen := map[string]bool{}
+	var suggestions []prompt.Suggest
+	for _, attribute := range s.collectionAttributes(collection) {
+		if !strings.HasPrefix(attribute, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(attribute, prefix)
+		if strings.Contains(name, ".") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		suggestions = append(suggestions, prompt.Suggest{Text: name, Description: collection + " attribute"})
+	}
+	return suggestions
+}
+
+// boundCollection returns the collection variable iterates over in a
+// `FOR variable IN collection` loop of tokens, resolving collection bind
+// parameters.
+func (s *ShellContext) boundCollection(tokens []aql.Token, variable string) string {
+	for i := len(tokens) - 4; i >= 0; i-- {
+		if !tokens[i].Is("FOR") || strings.Trim(tokens[i+1].Text, "`´") != variable || !tokens[i+2].Is("IN") {
+			continue
+		}
+		source := tokens[i+3]
+		switch source.Kind {
+		case aql.Identifier, aql.QuotedIdentifier:
+			return strings.Trim(source.Text, "`´")
+		case aql.BindParam:
+			if name, ok := s.BindVars[strings.TrimPrefix(source.Text, "@")].(string); ok {
+				return name
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// boundVariables returns the variables introduced by FOR and LET in tokens.
+func boundVariables(tokens []aql.Token) []string {
+	var variables []string
+	for i := 0; i+1 < len(tokens); i++ {
+		if (tokens[i].Is("FOR") || tokens[i].Is("LET")) && tokens[i+1].Kind == aql.Identifier {
+			variables = append(variables, tokens[i+1].Text)
+		}
+	}
+	return variables
+}
+
+func (s *ShellContext) bindParamSuggestions() []prompt.Suggest {
+	names := make([]string, 0, len(s.BindVars))
+	for name := range s.BindVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	suggestions := make([]prompt.Suggest, 0, len(names))
+	for _, name := range names {
+		suggestions = append(suggestions, prompt.Suggest{Text: "@" + name, Description: fmt.Sprintf("bind parameter = %v", s.BindVars[name])})
+	}
+	return suggestions
+}
+
+func (s *ShellContext) graphSuggestions(quote string) []prompt.Suggest {
+	var suggestions []prompt.Suggest
+	for _, graph := range s.catalog().graphs {
+		suggestions = append(suggestions, prompt.Suggest{Text: quote + graph + quote, Description: "graph"})
+	}
+	return suggestions
+}
+
+// keywordSuggestions are the AQL keywords, with the descriptions of the shell
+// suggestions where there is one.
+var keywordSuggestions = func() []prompt.Suggest {
+	descriptions := map[string]string{}
+	for _, suggestion := range shellSuggestions {
+		descriptions[suggestion.Text] = suggestion.Description
+	}
+	var suggestions []prompt.Suggest
+	for _, keyword := range aql.Keywords() {
+		description := descriptions[keyword]
+		if description == "" {
+			description = "AQL keyword"
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: keyword, Description: description})
+	}
+	return suggestions
+}()
+
+// functionSuggestions are the built-in AQL functions with their signatures.
+var functionSuggestions = func() []prompt.Suggest {
+	var suggestions []prompt.Suggest
+	for _, function := range aql.Functions() {
+		suggestions = append(suggestions, prompt.Suggest{Text: function.Name, Description: function.Signature})
+	}
+	return suggestions
+}()
+
+func namedSuggestions(names []string, description string) []prompt.Suggest {
+	suggestions := make([]prompt.Suggest, 0, len(names))
+	for _, name := range names {
+		suggestions = append(suggestions, prompt.Suggest{Text: name, Description: description})
+	}
+	return suggestions
+}
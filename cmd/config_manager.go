@@ -16,6 +16,9 @@ type DatabaseConfig struct {
 	Password string `yaml:"password"`
 	Database string `yaml:"database"`
 	SSL      bool   `yaml:"ssl"`
+	// Settings are the default query settings for sessions using this
+	// configuration, with the same names and values as /set.
+	Settings map[string]string `yaml:"settings,omitempty"`
 }
 
 type Config struct {
@@ -155,12 +158,26 @@ func (s *ShellContext) switchToConfig(configName string) {
 		return
 	}
 
+	settings, err := newQuerySettings(dbConfig.Settings)
+	if err != nil {
+		fmt.Printf("Warning: invalid settings in '%s', using defaults: %v\n", configName, err)
+		settings = defaultQuerySettings()
+	}
+
+	s.abortOpenTransaction("/switch")
+
 	s.Client = newShellCtx.Client
 	s.DB = newShellCtx.DB
 	s.CurrentDB = newShellCtx.CurrentDB
 	s.Config = newShellCtx.Config
 	s.ConnectionURL = newShellCtx.ConnectionURL
 	s.CurrentConfig = configName
+	s.Settings = settings
+	s.Completion = newShellCtx.Completion
+	if s.History != nil {
+		// Reload in place, the prompt's key bindings hold on to the history
+		*s.History = *loadHistory(s.ConfigManager, configName)
+	}
 
 	fmt.Printf("Successfully switched to '%s' (database: %s)\n", configName, s.CurrentDB)
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	driver "github.com/arangodb/go-driver"
 	"github.com/c-bata/go-prompt"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thakurankit7/arango-cli/aql"
 )
 
 var (
@@ -20,6 +23,7 @@ var (
 	buffer          strings.Builder
 	isMultilineMode bool
 	configName      string
+	batchSize       int
 )
 
 var shellCmd = &cobra.Command{
@@ -32,41 +36,18 @@ var shellCmd = &cobra.Command{
 			return fmt.Errorf("failed to initialize config manager: %v", err)
 		}
 
-		var config *ShellConfig
-		var currentConfigName string
-		if configName != "" {
-			// Connect using saved configuration
-			dbConfig, err := configManager.GetDatabaseConfig(configName)
-			if err != nil {
-				return fmt.Errorf("failed to get database config '%s': %v", configName, err)
-			}
-
-			config = &ShellConfig{
-				Host:     dbConfig.Host,
-				Port:     dbConfig.Port,
-				Username: dbConfig.Username,
-				Password: dbConfig.Password,
-				UseSSL:   dbConfig.SSL,
-				DBName:   dbConfig.Database,
-			}
-			currentConfigName = configName
-		} else {
-			// Connect using command line flags
-			config = &ShellConfig{
-				Host:     host,
-				Port:     port,
-				Username: username,
-				Password: password,
-				UseSSL:   useSSL,
-				DBName:   dbName,
-			}
-			currentConfigName = "manual"
+		config, currentConfigName, err := resolveShellConfig(configManager)
+		if err != nil {
+			return err
 		}
 
 		shellCtx, err := NewShellContextWithConfig(config, configManager, currentConfigName)
 		if err != nil {
 			return fmt.Errorf("failed to initialize shell: %v", err)
 		}
+		if cmd.Flags().Changed("batch-size") {
+			shellCtx.Settings.BatchSize = batchSize
+		}
 
 		fmt.Printf("Connected to ArangoDB at %s, database: %s\n", shellCtx.ConnectionURL, shellCtx.CurrentDB)
 		fmt.Println("Type 'help' for help, 'exit' to quit")
@@ -77,35 +58,124 @@ var shellCmd = &cobra.Command{
 	},
 }
 
-func completer(d prompt.Document) []prompt.Suggest {
-	// AQL keyword suggestions here
-	s := []prompt.Suggest{
-		{Text: "/show collections", Description: "List collections"},
-		{Text: "/col", Description: "List collections (shorthand)"},
-		{Text: "/show databases", Description: "List databases"},
-		{Text: "/db", Description: "List databases (shorthand)"},
-		{Text: "/use", Description: "Switch database"},
-		{Text: "FOR", Description: "AQL FOR loop"},
-		{Text: "RETURN", Description: "AQL RETURN statement"},
-		{Text: "FILTER", Description: "AQL FILTER statement"},
-		{Text: "SORT", Description: "AQL SORT statement"},
-		{Text: "LIMIT", Description: "AQL LIMIT statement"},
-		{Text: "LET", Description: "AQL variable assignment"},
-		{Text: "COLLECT", Description: "AQL COLLECT statement"},
-		{Text: "INSERT", Description: "AQL INSERT statement"},
-		{Text: "UPDATE", Description: "AQL UPDATE statement"},
-		{Text: "REPLACE", Description: "AQL REPLACE statement"},
-		{Text: "REMOVE", Description: "AQL REMOVE statement"},
-		{Text: "exit", Description: "Exit the shell"},
-		{Text: "quit", Description: "Exit the shell"},
-		{Text: "help", Description: "Show help"},
+// resolveShellConfig builds the connection settings either from the saved
+// configuration selected with --config or from the individual connection flags.
+func resolveShellConfig(configManager *ConfigManager) (*ShellConfig, string, error) {
+	if configName != "" {
+		// Connect using saved configuration
+		dbConfig, err := configManager.GetDatabaseConfig(configName)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get database config '%s': %v", configName, err)
+		}
+
+		return &ShellConfig{
+			Host:     dbConfig.Host,
+			Port:     dbConfig.Port,
+			Username: dbConfig.Username,
+			Password: dbConfig.Password,
+			UseSSL:   dbConfig.SSL,
+			DBName:   dbConfig.Database,
+		}, configName, nil
 	}
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+
+	// Connect using command line flags
+	return &ShellConfig{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		UseSSL:   useSSL,
+		DBName:   dbName,
+	}, "manual", nil
+}
+
+// connectFromFlags opens a shell context for non-interactive commands using the
+// connection flags registered by addConnectionFlags.
+func connectFromFlags() (*ShellContext, error) {
+	configManager, err := NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config manager: %v", err)
+	}
+
+	config, currentConfigName, err := resolveShellConfig(configManager)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewShellContextWithConfig(config, configManager, currentConfigName)
+}
+
+// addConnectionFlags registers the flags shared by every command that talks to
+// ArangoDB. Commands with subcommands pass their persistent flags.
+func addConnectionFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&host, "host", "H", "localhost", "ArangoDB host")
+	flags.IntVarP(&port, "port", "p", 8529, "ArangoDB port")
+	flags.StringVarP(&username, "username", "u", "root", "ArangoDB username")
+	flags.StringVarP(&password, "password", "P", "", "ArangoDB password")
+	flags.StringVarP(&dbName, "database", "d", "_system", "Database name to connect to")
+	flags.BoolVarP(&useSSL, "ssl", "s", false, "Use SSL for connection")
+	flags.StringVarP(&configName, "config", "c", "", "Saved configuration to connect with")
+}
+
+// shellSuggestions are the shell commands and the most common AQL keywords,
+// suggested at the start of the input.
+var shellSuggestions = []prompt.Suggest{
+	{Text: "/show collections", Description: "List collections"},
+	{Text: "/col", Description: "List collections (shorthand)"},
+	{Text: "/show databases", Description: "List databases"},
+	{Text: "/db", Description: "List databases (shorthand)"},
+	{Text: "/use", Description: "Switch database"},
+	{Text: "/format", Description: "Set the result output format"},
+	{Text: "/set", Description: "Set a bind parameter or query setting"},
+	{Text: "/unset", Description: "Remove a bind parameter or reset a setting"},
+	{Text: "/show settings", Description: "Show the query settings"},
+	{Text: "/collection", Description: "Create, drop, truncate, rename or inspect collections"},
+	{Text: "/get", Description: "Show a document by _id or collection and key"},
+	{Text: "/insert", Description: "Insert a document from JSON or @file.json"},
+	{Text: "/update", Description: "Update a document"},
+	{Text: "/replace", Description: "Replace a document"},
+	{Text: "/delete", Description: "Delete a document"},
+	{Text: "/edit", Description: "Edit a document in $EDITOR"},
+	{Text: "/import", Description: "Import documents from a JSON, JSONL or CSV file"},
+	{Text: "/export", Description: "Export a collection or query result to a file"},
+	{Text: "/dump", Description: "Dump the current database into a directory"},
+	{Text: "/restore", Description: "Restore a dump directory"},
+	{Text: "/transfer", Description: "Resumable copy of a collection to or from a JSONL file"},
+	{Text: "/indexes", Description: "List the indexes of a collection"},
+	{Text: "/index", Description: "Create or drop indexes"},
+	{Text: "/begin", Description: "Begin a stream transaction"},
+	{Text: "/commit", Description: "Commit the running transaction"},
+	{Text: "/abort", Description: "Abort the running transaction"},
+	{Text: "/params", Description: "List bind parameters"},
+	{Text: "/explain", Description: "Show the execution plan of a query"},
+	{Text: "/profile", Description: "Run a query and show per-node runtimes"},
+	{Text: "/tui", Description: "Open the full-screen workbench"},
+	{Text: "/history", Description: "List executed queries, optionally filtered"},
+	{Text: "/rerun", Description: "Run a query from the history again"},
+	{Text: "FOR", Description: "AQL FOR loop"},
+	{Text: "RETURN", Description: "AQL RETURN statement"},
+	{Text: "FILTER", Description: "AQL FILTER statement"},
+	{Text: "SORT", Description: "AQL SORT statement"},
+	{Text: "LIMIT", Description: "AQL LIMIT statement"},
+	{Text: "LET", Description: "AQL variable assignment"},
+	{Text: "COLLECT", Description: "AQL COLLECT statement"},
+	{Text: "INSERT", Description: "AQL INSERT statement"},
+	{Text: "UPDATE", Description: "AQL UPDATE statement"},
+	{Text: "REPLACE", Description: "AQL REPLACE statement"},
+	{Text: "REMOVE", Description: "AQL REMOVE statement"},
+	{Text: "exit", Description: "Exit the shell"},
+	{Text: "quit", Description: "Exit the shell"},
+	{Text: "help", Description: "Show help"},
 }
 
 func (s *ShellContext) handleSpecialCommands(input string) bool {
 	lowerInput := strings.ToLower(input)
 	parts := strings.Fields(input)
+	// Commands match regardless of case, their arguments keep it
+	command := ""
+	if len(parts) > 0 {
+		command = strings.ToLower(parts[0])
+	}
 
 	switch true {
 	case lowerInput == "/show databases" || lowerInput == "/db":
@@ -127,6 +197,91 @@ func (s *ShellContext) handleSpecialCommands(input string) bool {
 			fmt.Println("Usage: /switch <config_name>")
 		}
 		return true
+	case lowerInput == "/format" || strings.HasPrefix(lowerInput, "/format "):
+		s.setFormat(strings.TrimSpace(strings.TrimPrefix(input, "/format")))
+		return true
+	case lowerInput == "/set" || strings.HasPrefix(lowerInput, "/set "):
+		name, value, _ := strings.Cut(strings.TrimSpace(input[len("/set"):]), " ")
+		if strings.HasPrefix(name, "@") {
+			s.setParam(name, strings.TrimSpace(value))
+		} else {
+			s.setSetting(name, strings.TrimSpace(value))
+		}
+		return true
+	case lowerInput == "/unset" || strings.HasPrefix(lowerInput, "/unset "):
+		name := strings.TrimSpace(strings.TrimPrefix(input, "/unset"))
+		if name == "" || strings.HasPrefix(name, "@") {
+			s.unsetParam(name)
+		} else {
+			s.resetSetting(name)
+		}
+		return true
+	case lowerInput == "/show settings" || lowerInput == "/settings":
+		s.showSettings()
+		return true
+	case lowerInput == "/params":
+		s.showParams()
+		return true
+	case lowerInput == "/explain" || strings.HasPrefix(lowerInput, "/explain "):
+		s.showPlan(input[len("/explain"):], false)
+		return true
+	case lowerInput == "/profile" || strings.HasPrefix(lowerInput, "/profile "):
+		s.showPlan(input[len("/profile"):], true)
+		return true
+	case lowerInput == "/begin" || strings.HasPrefix(lowerInput, "/begin "):
+		s.beginTransaction(parts[1:])
+		return true
+	case lowerInput == "/commit":
+		s.commitTransaction()
+		return true
+	case lowerInput == "/abort":
+		s.abortTransaction()
+		return true
+	case lowerInput == "/collection" || strings.HasPrefix(lowerInput, "/collection "):
+		s.runShellCommand(newCollectionCmd("/collection", s.session), parts[1:])
+		s.Completion.refresh()
+		return true
+	case documentCommands[command]:
+		s.documentCommand(command, shellFields(input)[1:])
+		return true
+	case command == "/edit":
+		s.editDocument(parts[1:])
+		return true
+	case command == "/import":
+		s.runShellCommand(newImportCmd("/import", s.session), shellFields(input)[1:])
+		s.Completion.refresh()
+		return true
+	case command == "/export":
+		s.runShellCommand(newExportCmd("/export", s.session), shellFields(input)[1:])
+		return true
+	case command == "/dump":
+		s.runShellCommand(newDumpCmd("/dump", s.session), shellFields(input)[1:])
+		return true
+	case command == "/restore":
+		s.runShellCommand(newRestoreCmd("/restore", s.session), shellFields(input)[1:])
+		s.Completion.refresh()
+		return true
+	case command == "/transfer":
+		s.runShellCommand(newTransferCmd("/transfer", s.session), shellFields(input)[1:])
+		s.Completion.refresh()
+		return true
+	case lowerInput == "/indexes" || strings.HasPrefix(lowerInput, "/indexes "):
+		s.runShellCommand(newIndexListCmd(s.session), parts[1:])
+		return true
+	case lowerInput == "/index" || strings.HasPrefix(lowerInput, "/index "):
+		s.runShellCommand(newIndexCmd("/index", s.session), parts[1:])
+		return true
+	case lowerInput == "/history" || strings.HasPrefix(lowerInput, "/history "):
+		s.showHistory(strings.TrimSpace(input[len("/history"):]))
+		return true
+	case lowerInput == "/rerun" || strings.HasPrefix(lowerInput, "/rerun "):
+		s.rerun(strings.TrimSpace(input[len("/rerun"):]))
+		return true
+	case lowerInput == "/tui":
+		if err := s.runWorkbench(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		return true
 	case lowerInput == "/current":
 		s.showCurrentConnection()
 		return true
@@ -173,88 +328,229 @@ func (s *ShellContext) useDatabase(dbName string) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	s.abortOpenTransaction("database switch")
 	s.DB = newDb
 	s.CurrentDB = dbName
 	fmt.Printf("Using database '%s'\n", dbName)
 }
 
 func (s *ShellContext) executor(input string) {
-	// Check if line ends with a semi colon (force execution)
-	if strings.HasSuffix(strings.TrimSpace(input), ";") {
-		trimmedInput := strings.TrimSpace(input)
-		trimmedInput = trimmedInput[:len(trimmedInput)-1]
-
-		if isMultilineMode {
-			// Finalize the multiline query and execute
-			fullQuery := buffer.String() + trimmedInput
-			buffer.Reset()
-			isMultilineMode = false
-			s.executeQuery(fullQuery)
-		} else {
-			// Execute single line query (without the semi colon)
-			s.executeQuery(trimmedInput)
-		}
-		return
-	}
+	// Statements run once they are terminated by a semicolon; anything after
+	// the last semicolon stays in the buffer as the start of the next statement.
+	buffer.WriteString(input + "\n")
+	statements, rest := aql.SplitComplete(buffer.String())
 
-	// If we're already in multiline mode, add this line to the buffer
-	if isMultilineMode {
-		buffer.WriteString(input + "\n")
-		return
+	buffer.Reset()
+	if rest != "" {
+		buffer.WriteString(rest + "\n")
 	}
+	isMultilineMode = rest != ""
 
-	// start multiline mode
-	buffer.WriteString(input + "\n")
-	isMultilineMode = true
+	for _, statement := range statements {
+		s.executeQuery(statement)
+	}
 }
 
-func (s *ShellContext) executeQuery(query string) {
-	if !strings.Contains(strings.ToUpper(query), "RETURN") &&
-		!strings.Contains(strings.ToUpper(query), "INSERT") &&
-		!strings.Contains(strings.ToUpper(query), "UPDATE") &&
-		!strings.Contains(strings.ToUpper(query), "REMOVE") &&
-		!strings.Contains(strings.ToUpper(query), "REPLACE") {
+// normalizeQuery turns a bare expression into a query by prepending RETURN.
+func normalizeQuery(query string) string {
+	if aql.IsExpression(query) {
 		query = "RETURN " + query
 	}
+	return query
+}
+
+// queryContext derives the context queries are executed with from parent,
+// adding the query options of the session.
+func (s *ShellContext) queryContext(parent context.Context) context.Context {
+	return s.Settings.apply(s.transactionContext(parent))
+}
 
-	cursor, err := s.DB.Query(s.Context, query, nil)
+// transactionContext routes requests made with the returned context through
+// the running stream transaction, if there is one.
+func (s *ShellContext) transactionContext(parent context.Context) context.Context {
+	if s.Transaction == "" {
+		return parent
+	}
+	return driver.WithTransactionID(parent, s.Transaction)
+}
+
+// openCursor starts query with the session settings applied.
+func (s *ShellContext) openCursor(ctx context.Context, query string, bindVars map[string]interface{}) (driver.Cursor, error) {
+	// The driver does not forward failOnWarning, and checking the warnings
+	// of the response is too late for queries that write
+	if s.Settings.FailOnWarning {
+		return s.openAPICursor(ctx, query, bindVars)
+	}
+	return s.DB.Query(s.queryContext(ctx), normalizeQuery(query), bindVars)
+}
+
+// runQuery executes query against the current database and reads the whole cursor.
+func (s *ShellContext) runQuery(ctx context.Context, query string, bindVars map[string]interface{}) ([]interface{}, driver.QueryStatistics, error) {
+	cursor, err := s.openCursor(ctx, query, bindVars)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
+		return nil, nil, err
 	}
 	defer cursor.Close()
 
 	var resultData []interface{}
-	count := 0
 	for {
 		var doc interface{}
-		_, err := cursor.ReadDocument(s.Context, &doc)
+		_, err := cursor.ReadDocument(ctx, &doc)
 		if driver.IsNoMoreDocuments(err) {
 			break
 		} else if err != nil {
-			ShowPopup(fmt.Sprintf("Error reading result: %v", err))
-			return
+			return nil, nil, fmt.Errorf("error reading result: %v", err)
 		}
 		resultData = append(resultData, doc)
-		count++
 	}
-	stats := cursor.Statistics()
+	return resultData, cursor.Statistics(), nil
+}
+
+func (s *ShellContext) executeQuery(query string) {
+	entry := historyEntry{Time: time.Now(), Query: query, Status: historyOK}
+	defer func() { s.History.add(entry) }()
+	fail := func(status string, err error) {
+		entry.Status = status
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entry.DurationMs = time.Since(entry.Time).Milliseconds()
+	}
+
+	bindVars, ok := s.resolveBindVars(query)
+	if !ok {
+		fail(historyFailed, fmt.Errorf("missing bind parameters"))
+		return
+	}
+
+	var formatter ResultFormatter
+	if s.Format != defaultShellFormat {
+		var err error
+		if formatter, err = getFormatter(s.Format); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			fail(historyFailed, err)
+			return
+		}
+	}
+
+	run := s.startInterruptible()
+	defer run.stop()
+
+	cursor, err := s.openCursor(run.ctx, query, bindVars)
+	if err != nil {
+		if run.wasInterrupted() {
+			fmt.Println(s.killQuery(normalizeQuery(query), run.started))
+			fail(historyCancelled, nil)
+			return
+		}
+		fmt.Printf("Error: %v\n", err)
+		fail(historyFailed, err)
+		return
+	}
+	entry.DurationMs = time.Since(entry.Time).Milliseconds()
+
+	results := newResultStream(run.ctx, cursor, s.Settings.BatchSize, resultRenderer(formatter, cursor.Statistics()))
+	defer results.close()
+	ShowResultStream(s, results)
+}
+
+// resultRenderer returns the renderer of a result stream. Without a
+// formatter the documents are shown as JSON, followed by the statistics
+// once all are loaded. Formats other than JSON lines need all documents.
+func resultRenderer(formatter ResultFormatter, stats driver.QueryStatistics) streamRenderer {
+	format := func(docs []interface{}) string {
+		var sb strings.Builder
+		if err := formatter.Format(&sb, docs); err != nil {
+			return fmt.Sprintf("Error: %v\n", err)
+		}
+		return sb.String()
+	}
+	if formatter != nil {
+		// JSON lines can be appended batch by batch
+		if _, ok := formatter.(jsonLinesFormatter); ok {
+			return streamRenderer{
+				batch:  func(docs []interface{}, first bool) string { return format(docs) },
+				finish: func(docs []interface{}) string { return "" },
+			}
+		}
+		return streamRenderer{finish: format, whole: true}
+	}
+	return streamRenderer{
+		batch: func(docs []interface{}, first bool) string {
+			if first {
+				return "📊 Results:\n\n" + formatJSONArray(docs)
+			}
+			return formatJSONArray(docs)
+		},
+		finish: func(docs []interface{}) string { return formatStatistics(stats) },
+	}
+}
+
+// showPlan explains or profiles query and shows the rendered plan in the viewer.
+func (s *ShellContext) showPlan(query string, profile bool) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if query == "" {
+		if profile {
+			fmt.Println("Usage: /profile <aql>")
+		} else {
+			fmt.Println("Usage: /explain <aql>")
+		}
+		return
+	}
+
+	bindVars, ok := s.resolveBindVars(query)
+	if !ok {
+		return
+	}
 
-	// Format the data and show in popup
-	formattedData := FormatQueryResult(resultData, stats)
-	ShowPopup(formattedData)
+	var (
+		rendered string
+		err      error
+		title    = "Execution Plan"
+	)
+	run := s.startInterruptible()
+	defer run.stop()
+
+	if profile {
+		rendered, err = s.profileQuery(run.ctx, query, bindVars)
+		title = "Query Profile"
+	} else {
+		rendered, err = s.explainQuery(query, bindVars)
+	}
+	if err != nil {
+		if run.wasInterrupted() {
+			fmt.Println(s.killQuery(normalizeQuery(query), run.started))
+			return
+		}
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	ShowPopupWithTitle(title, rendered)
+}
+
+func (s *ShellContext) setFormat(name string) {
+	if name == "" {
+		fmt.Printf("Current format: %s\n", s.Format)
+		fmt.Printf("Available formats: %s, %s\n", defaultShellFormat, strings.Join(formatNames(), ", "))
+		return
+	}
+
+	name = strings.ToLower(name)
+	if name != defaultShellFormat {
+		if _, err := getFormatter(name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	s.Format = name
+	fmt.Printf("Output format set to '%s'\n", name)
 }
 
 func init() {
 	rootCmd.AddCommand(shellCmd)
 
-	// Reuse the same flags from the connect command
-	shellCmd.Flags().StringVarP(&host, "host", "H", "localhost", "ArangoDB host")
-	shellCmd.Flags().IntVarP(&port, "port", "p", 8529, "ArangoDB port")
-	shellCmd.Flags().StringVarP(&username, "username", "u", "root", "ArangoDB username")
-	shellCmd.Flags().StringVarP(&password, "password", "P", "", "ArangoDB password")
-	shellCmd.Flags().StringVarP(&dbName, "database", "d", "_system", "Database name to connect to")
-	shellCmd.Flags().BoolVarP(&useSSL, "ssl", "s", false, "Use SSL for connection")
+	addConnectionFlags(shellCmd.Flags())
+	shellCmd.Flags().IntVar(&batchSize, "batch-size", defaultBatchSize, "Number of documents fetched per cursor batch")
 
 	shellCmd.MarkFlagRequired("password")
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+)
+
+// apiCursor is a cursor opened through the cursor API directly, for query
+// options the driver does not forward, like failOnWarning.
+type apiCursor struct {
+	s       *ShellContext
+	headers map[string]string
+
+	id      string
+	result  []json.RawMessage
+	index   int
+	hasMore bool
+	count   int64
+	stats   cursorStatistics
+}
+
+// cursorResponse is a batch returned by the cursor API.
+type cursorResponse struct {
+	ID      string            `json:"id"`
+	Result  []json.RawMessage `json:"result"`
+	HasMore bool              `json:"hasMore"`
+	Count   int64             `json:"count"`
+	Extra   struct {
+		Stats cursorStatistics `json:"stats"`
+	} `json:"extra"`
+}
+
+// cursorStatistics implements driver.QueryStatistics for apiCursor.
+type cursorStatistics struct {
+	Writes      int64   `json:"writesExecuted"`
+	Ignored     int64   `json:"writesIgnored"`
+	Full        int64   `json:"scannedFull"`
+	Index       int64   `json:"scannedIndex"`
+	Filter      int64   `json:"filtered"`
+	Total       int64   `json:"fullCount"`
+	ElapsedTime float64 `json:"executionTime"`
+}
+
+func (c cursorStatistics) WritesExecuted() int64 { return c.Writes }
+func (c cursorStatistics) WritesIgnored() int64  { return c.Ignored }
+func (c cursorStatistics) ScannedFull() int64    { return c.Full }
+func (c cursorStatistics) ScannedIndex() int64   { return c.Index }
+func (c cursorStatistics) Filtered() int64       { return c.Filter }
+func (c cursorStatistics) FullCount() int64      { return c.Total }
+func (c cursorStatistics) ExecutionTime() time.Duration {
+	return time.Duration(c.ElapsedTime * float64(time.Second))
+}
+
+// openAPICursor starts query with the session settings sent as options of
+// the cursor request, so the server itself fails a query that warns before
+// it commits any changes.
+func (s *ShellContext) openAPICursor(ctx context.Context, query string, bindVars map[string]interface{}) (driver.Cursor, error) {
+	q := s.Settings
+	options := map[string]interface{}{"failOnWarning": q.FailOnWarning}
+	if q.MaxRuntime > 0 {
+		options["maxRuntime"] = q.MaxRuntime
+	}
+	if q.FullCount {
+		options["fullCount"] = true
+	}
+	if len(q.OptimizerRules) > 0 {
+		options["optimizer"] = map[string]interface{}{"rules": q.OptimizerRules}
+	}
+	body := map[string]interface{}{
+		"query":   normalizeQuery(query),
+		"count":   q.Count,
+		"cache":   q.Cache,
+		"options": options,
+	}
+	if len(bindVars) > 0 {
+		body["bindVars"] = bindVars
+	}
+	if q.BatchSize > 0 {
+		body["batchSize"] = q.BatchSize
+	}
+	if q.MemoryLimit > 0 {
+		body["memoryLimit"] = q.MemoryLimit
+	}
+
+	headers := map[string]string{}
+	if s.Transaction != "" {
+		headers["x-arango-trx-id"] = string(s.Transaction)
+	}
+	if q.AllowDirtyReads {
+		headers["x-arango-allow-dirty-read"] = "true"
+	}
+
+	c := &apiCursor{s: s, headers: headers}
+	var response cursorResponse
+	if err := s.apiRequestWithHeaders(ctx, "POST", "_api/cursor", headers, body, &response, 201); err != nil {
+		return nil, err
+	}
+	c.apply(response)
+	c.count = response.Count
+	return c, nil
+}
+
+func (c *apiCursor) apply(response cursorResponse) {
+	c.id = response.ID
+	c.result = response.Result
+	c.index = 0
+	c.hasMore = response.HasMore
+	// Batches without extra keep the statistics seen so far
+	if response.Extra.Stats != (cursorStatistics{}) {
+		c.stats = response.Extra.Stats
+	}
+}
+
+func (c *apiCursor) HasMore() bool {
+	return c.index < len(c.result) || c.hasMore
+}
+
+func (c *apiCursor) ReadDocument(ctx context.Context, result interface{}) (driver.DocumentMeta, error) {
+	if c.index >= len(c.result) {
+		if !c.hasMore {
+			return driver.DocumentMeta{}, driver.NoMoreDocumentsError{}
+		}
+		var response cursorResponse
+		if err := c.s.apiRequestWithHeaders(ctx, "POST", path.Join("_api/cursor", c.id), c.headers, nil, &response, 200); err != nil {
+			return driver.DocumentMeta{}, err
+		}
+		c.apply(response)
+		if len(c.result) == 0 {
+			return driver.DocumentMeta{}, driver.NoMoreDocumentsError{}
+		}
+	}
+
+	raw := c.result[c.index]
+	c.index++
+	var meta driver.DocumentMeta
+	// Results that aren't documents have no metadata
+	json.Unmarshal(raw, &meta)
+	if err := json.Unmarshal(raw, result); err != nil {
+		return meta, err
+	}
+	return meta, nil
+}
+
+func (c *apiCursor) RetryReadDocument(ctx context.Context, result interface{}) (driver.DocumentMeta, error) {
+	return driver.DocumentMeta{}, fmt.Errorf("retrying reads is not supported with failOnWarning")
+}
+
+func (c *apiCursor) Count() int64 {
+	return c.count
+}
+
+// Statistics returns the statistics of the latest batch, so they are
+// complete once all batches are read.
+func (c *apiCursor) Statistics() driver.QueryStatistics {
+	return &c.stats
+}
+
+func (c *apiCursor) Extra() driver.QueryExtra {
+	return nil
+}
+
+// Close deletes the cursor on the server if it has more documents.
+func (c *apiCursor) Close() error {
+	if !c.hasMore || c.id == "" {
+		return nil
+	}
+	c.hasMore = false
+	return c.s.apiRequestWithHeaders(context.Background(), "DELETE", path.Join("_api/cursor", c.id), c.headers, nil, nil, 202)
+}
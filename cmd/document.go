@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/pflag"
+)
+
+// documentOptions holds the flags of the document commands. Each command only
+// registers the flags that the collection API supports for it.
+type documentOptions struct {
+	rev           string
+	returnNew     bool
+	returnOld     bool
+	overwriteMode string
+	keepNull      bool
+	mergeObjects  bool
+	waitForSync   bool
+}
+
+// documentCommands are the shell commands run by documentCommand. /remove is
+// an alias of /delete.
+var documentCommands = map[string]bool{
+	"/get": true, "/insert": true, "/update": true, "/replace": true,
+	"/delete": true, "/remove": true,
+}
+
+// documentCommand runs /get, /insert, /update, /replace or /delete. Documents
+// are addressed by _id (users/123) or by collection and key (users 123).
+func (s *ShellContext) documentCommand(command string, args []string) {
+	if command == "/remove" {
+		command = "/delete"
+	}
+	o := documentOptions{keepNull: true, mergeObjects: true}
+	fs := newShellFlagSet(command)
+	switch command {
+	case "/insert":
+		fs.StringVar(&o.overwriteMode, "overwrite-mode", "", "What to do if the _key exists: ignore, replace, update or conflict")
+		fs.BoolVar(&o.returnNew, "return-new", false, "Show the stored document")
+		fs.BoolVar(&o.keepNull, "keep-null", true, "Keep attributes set to null (overwrite mode update)")
+		fs.BoolVar(&o.mergeObjects, "merge-objects", true, "Merge nested objects (overwrite mode update)")
+	case "/update", "/replace":
+		fs.StringVar(&o.rev, "rev", "", "Only write if the document still has this revision")
+		fs.BoolVar(&o.returnNew, "return-new", false, "Show the stored document")
+		fs.BoolVar(&o.returnOld, "return-old", false, "Show the previous document")
+		if command == "/update" {
+			fs.BoolVar(&o.keepNull, "keep-null", true, "Keep attributes set to null instead of removing them")
+			fs.BoolVar(&o.mergeObjects, "merge-objects", true, "Merge nested objects instead of replacing them")
+		}
+	case "/delete":
+		fs.StringVar(&o.rev, "rev", "", "Only remove if the document still has this revision")
+		fs.BoolVar(&o.returnOld, "return-old", false, "Show the removed document")
+	}
+	if command != "/get" {
+		fs.BoolVar(&o.waitForSync, "wait-for-sync", false, "Wait until the write is synced to disk")
+	}
+	if err := fs.Parse(args); err != nil {
+		if err != pflag.ErrHelp {
+			fmt.Printf("Error: %v\n", err)
+		}
+		return
+	}
+
+	var err error
+	switch command {
+	case "/get":
+		err = s.getDocument(fs.Args())
+	case "/insert":
+		err = s.insertDocument(fs.Args(), o)
+	case "/update", "/replace":
+		err = s.writeDocument(command, fs.Args(), o)
+	case "/delete":
+		err = s.deleteDocument(fs.Args(), o)
+	}
+	if driver.IsPreconditionFailed(err) {
+		fmt.Println("Error: the document was changed by someone else, its revision no longer matches")
+	} else if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// splitDocumentRef reads a document reference from the start of args, either
+// "collection/key" or "collection key", and returns the remaining arguments.
+func splitDocumentRef(args []string) (string, string, []string, error) {
+	if len(args) == 0 {
+		return "", "", nil, fmt.Errorf("missing document, use <collection>/<key> or <collection> <key>")
+	}
+	if collection, key, ok := strings.Cut(args[0], "/"); ok {
+		return collection, key, args[1:], nil
+	}
+	if len(args) < 2 {
+		return "", "", nil, fmt.Errorf("missing key of document in '%s'", args[0])
+	}
+	return args[0], args[1], args[2:], nil
+}
+
+// parseDocumentArg parses inline JSON, or reads the JSON file of an argument
+// written as @file.json.
+func parseDocumentArg(args []string) (map[string]interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected one JSON document or @file.json")
+	}
+	data := []byte(args[0])
+	if file, ok := strings.CutPrefix(args[0], "@"); ok {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("failed to read document file: %v", err)
+		}
+	}
+	// Numbers are kept as json.Number so large integers are written exactly
+	doc, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("document must be a JSON object: %v", err)
+	}
+	return doc, nil
+}
+
+func (s *ShellContext) getDocument(args []string) error {
+	collection, key, rest, err := splitDocumentRef(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	col, err := s.DB.Collection(s.Context, collection)
+	if err != nil {
+		return err
+	}
+
+	var doc map[string]interface{}
+	if _, err := col.ReadDocument(s.transactionContext(s.Context), key, &doc); err != nil {
+		return err
+	}
+	return s.showDocument(collection+"/"+key, doc)
+}
+
+func (s *ShellContext) insertDocument(args []string, o documentOptions) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: /insert <collection> <json|@file.json>")
+	}
+	doc, err := parseDocumentArg(args[1:])
+	if err != nil {
+		return err
+	}
+	col, err := s.DB.Collection(s.Context, args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, result := o.context(s)
+	if o.overwriteMode != "" {
+		mode := driver.OverwriteMode(strings.ToLower(o.overwriteMode))
+		switch mode {
+		case driver.OverwriteModeIgnore, driver.OverwriteModeReplace, driver.OverwriteModeUpdate, driver.OverwriteModeConflict:
+		default:
+			return fmt.Errorf("unknown overwrite mode '%s' (available: ignore, replace, update, conflict)", o.overwriteMode)
+		}
+		ctx = driver.WithOverwriteMode(ctx, mode)
+	}
+
+	meta, err := col.CreateDocument(ctx, doc)
+	if err != nil {
+		return err
+	}
+	return s.showDocument("Inserted "+meta.ID.String(), result.with(meta))
+}
+
+// writeDocument updates or replaces a document. A _rev in the new document is
+// used as precondition unless --rev is given.
+func (s *ShellContext) writeDocument(command string, args []string, o documentOptions) error {
+	collection, key, rest, err := splitDocumentRef(args)
+	if err != nil {
+		return err
+	}
+	doc, err := parseDocumentArg(rest)
+	if err != nil {
+		return err
+	}
+	col, err := s.DB.Collection(s.Context, collection)
+	if err != nil {
+		return err
+	}
+	if rev, ok := doc["_rev"].(string); ok && o.rev == "" {
+		o.rev = rev
+	}
+
+	ctx, result := o.context(s)
+	var meta driver.DocumentMeta
+	if command == "/update" {
+		meta, err = col.UpdateDocument(ctx, key, doc)
+	} else {
+		meta, err = col.ReplaceDocument(ctx, key, doc)
+	}
+	if err != nil {
+		return err
+	}
+	verb := "Updated "
+	if command == "/replace" {
+		verb = "Replaced "
+	}
+	return s.showDocument(verb+meta.ID.String(), result.with(meta))
+}
+
+func (s *ShellContext) deleteDocument(args []string, o documentOptions) error {
+	collection, key, rest, err := splitDocumentRef(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	col, err := s.DB.Collection(s.Context, collection)
+	if err != nil {
+		return err
+	}
+
+	ctx, result := o.context(s)
+	meta, err := col.RemoveDocument(ctx, key)
+	if err != nil {
+		return err
+	}
+	return s.showDocument("Deleted "+meta.ID.String(), result.with(meta))
+}
+
+// documentResult collects what a write returns besides the document meta.
+type documentResult struct {
+	new map[string]interface{}
+	old map[string]interface{}
+}
+
+// context returns the request context for a document write, joining a
+// running transaction and applying the write options.
+func (o documentOptions) context(s *ShellContext) (context.Context, *documentResult) {
+	result := &documentResult{}
+	ctx := s.transactionContext(s.Context)
+	if o.rev != "" {
+		ctx = driver.WithRevision(ctx, o.rev)
+	}
+	if o.returnNew {
+		ctx = driver.WithReturnNew(ctx, &result.new)
+	}
+	if o.returnOld {
+		ctx = driver.WithReturnOld(ctx, &result.old)
+	}
+	if !o.keepNull {
+		ctx = driver.WithKeepNull(ctx, false)
+	}
+	if !o.mergeObjects {
+		ctx = driver.WithMergeObjects(ctx, false)
+	}
+	if o.waitForSync {
+		ctx = driver.WithWaitForSync(ctx, true)
+	}
+	return ctx, result
+}
+
+// with returns the document shown after a write: the meta data of the
+// document plus the new and old versions if they were requested.
+func (r *documentResult) with(meta driver.DocumentMeta) map[string]interface{} {
+	doc := map[string]interface{}{
+		"_id":  meta.ID.String(),
+		"_key": meta.Key,
+		"_rev": meta.Rev,
+	}
+	if meta.OldRev != "" {
+		doc["_oldRev"] = meta.OldRev
+	}
+	if r.new != nil {
+		doc["new"] = r.new
+	}
+	if r.old != nil {
+		doc["old"] = r.old
+	}
+	return doc
+}
+
+// showDocument shows a single document in the viewer using the session's
+// output format.
+func (s *ShellContext) showDocument(title string, doc map[string]interface{}) error {
+	docs := []interface{}{doc}
+	content := "📊 Results:\n\n" + formatJSONArray(docs)
+	if s.Format != defaultShellFormat {
+		formatter, err := getFormatter(s.Format)
+		if err != nil {
+			return err
+		}
+		var sb strings.Builder
+		if err := formatter.Format(&sb, docs); err != nil {
+			return err
+		}
+		content = sb.String()
+	}
+	return ShowDocuments(s, title, content, docs)
+}
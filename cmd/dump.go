@@ -0,0 +1,635 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/cobra"
+)
+
+// A dump directory contains dump.json with the list of collections, a
+// <collection>.structure.json and <collection>.data.jsonl per collection,
+// and analyzers.json, views.json and graphs.json.
+const dumpManifestFile = "dump.json"
+
+// dumpManifest describes a dump directory.
+type dumpManifest struct {
+	Database    string    `json:"database"`
+	Created     time.Time `json:"created"`
+	Server      string    `json:"server"`
+	Collections []string  `json:"collections"`
+}
+
+// collectionStructure holds the definition of a collection as returned by
+// the collection and index APIs, so that no option is lost on restore.
+type collectionStructure struct {
+	Parameters map[string]interface{}   `json:"parameters"`
+	Indexes    []map[string]interface{} `json:"indexes"`
+}
+
+// collectionFilter selects collections by the --collection and
+// --exclude-collection flags.
+type collectionFilter struct {
+	include []string
+	exclude []string
+}
+
+func (f collectionFilter) matches(name string) bool {
+	for _, excluded := range f.exclude {
+		if excluded == name {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, included := range f.include {
+		if included == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newDumpCmd builds the dump command. The same command backs `arango-cli
+// dump` and the shell's /dump command.
+func newDumpCmd(use string, session sessionFunc) *cobra.Command {
+	var (
+		filter        collectionFilter
+		output        string
+		includeSystem bool
+		overwrite     bool
+		batchSize     int
+	)
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Dump the current database into a directory",
+		Long: `Write the collections of the current database to a directory: the
+collection definitions and indexes as JSON, the documents as one JSONL file per
+collection, and the analyzers, views and named graphs of the database. The
+directory can be loaded into another database with restore.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			return s.dumpDatabase(output, filter, includeSystem, overwrite, batchSize)
+		},
+	}
+	c.Flags().StringVarP(&output, "output", "o", "", "Directory to write the dump to")
+	c.Flags().BoolVar(&includeSystem, "include-system", false, "Also dump system collections")
+	c.Flags().BoolVar(&overwrite, "overwrite", false, "Write into a directory that already contains a dump")
+	c.Flags().IntVar(&batchSize, "batch-size", defaultBatchSize, "Documents fetched per cursor batch")
+	c.Flags().StringSliceVar(&filter.include, "collection", nil, "Only dump these collections (comma separated or repeated)")
+	c.Flags().StringSliceVar(&filter.exclude, "exclude-collection", nil, "Leave out these collections")
+	c.MarkFlagRequired("output")
+	return c
+}
+
+func (s *ShellContext) dumpDatabase(output string, filter collectionFilter, includeSystem, overwrite bool, batchSize int) error {
+	if _, err := os.Stat(filepath.Join(output, dumpManifestFile)); err == nil && !overwrite {
+		return fmt.Errorf("%s already contains a dump, use --overwrite to replace it", output)
+	}
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return err
+	}
+
+	cols, err := s.DB.Collections(s.Context)
+	if err != nil {
+		return err
+	}
+	manifest := dumpManifest{Database: s.CurrentDB, Created: time.Now().UTC()}
+	if version, err := s.Client.Version(s.Context); err == nil {
+		manifest.Server = string(version.Version)
+	}
+
+	for _, col := range cols {
+		name := col.Name()
+		if (strings.HasPrefix(name, "_") && !includeSystem) || !filter.matches(name) {
+			continue
+		}
+
+		var structure collectionStructure
+		if err := s.apiRequest(s.Context, "GET", "_api/collection/"+url.PathEscape(name)+"/properties", nil, &structure.Parameters, http.StatusOK); err != nil {
+			return fmt.Errorf("failed to read properties of '%s': %v", name, err)
+		}
+		var indexes struct {
+			Indexes []map[string]interface{} `json:"indexes"`
+		}
+		if err := s.apiRequest(s.Context, "GET", "_api/index?collection="+url.QueryEscape(name), nil, &indexes, http.StatusOK); err != nil {
+			return fmt.Errorf("failed to read indexes of '%s': %v", name, err)
+		}
+		structure.Indexes = indexes.Indexes
+		if err := writeJSONFile(filepath.Join(output, name+".structure.json"), structure); err != nil {
+			return err
+		}
+
+		count, err := s.dumpCollectionData(filepath.Join(output, name+".data.jsonl"), name, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to dump documents of '%s': %v", name, err)
+		}
+		fmt.Printf("Dumped %s: %d documents, %d indexes\n", name, count, len(structure.Indexes))
+		manifest.Collections = append(manifest.Collections, name)
+	}
+
+	var analyzers struct {
+		Result []map[string]interface{} `json:"result"`
+	}
+	if err := s.apiRequest(s.Context, "GET", "_api/analyzer", nil, &analyzers, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to read analyzers: %v", err)
+	}
+	// Built-in analyzers have no database prefix
+	custom := []map[string]interface{}{}
+	for _, analyzer := range analyzers.Result {
+		if strings.Contains(stringField(analyzer, "name"), "::") {
+			custom = append(custom, analyzer)
+		}
+	}
+	if err := writeJSONFile(filepath.Join(output, "analyzers.json"), custom); err != nil {
+		return err
+	}
+
+	var views struct {
+		Result []map[string]interface{} `json:"result"`
+	}
+	if err := s.apiRequest(s.Context, "GET", "_api/view", nil, &views, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to read views: %v", err)
+	}
+	viewProperties := []map[string]interface{}{}
+	for _, view := range views.Result {
+		var properties map[string]interface{}
+		if err := s.apiRequest(s.Context, "GET", "_api/view/"+url.PathEscape(stringField(view, "name"))+"/properties", nil, &properties, http.StatusOK); err != nil {
+			return fmt.Errorf("failed to read view '%s': %v", stringField(view, "name"), err)
+		}
+		viewProperties = append(viewProperties, properties)
+	}
+	if err := writeJSONFile(filepath.Join(output, "views.json"), viewProperties); err != nil {
+		return err
+	}
+
+	var graphs struct {
+		Graphs []map[string]interface{} `json:"graphs"`
+	}
+	if err := s.apiRequest(s.Context, "GET", "_api/gharial", nil, &graphs, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to read graphs: %v", err)
+	}
+	if err := writeJSONFile(filepath.Join(output, "graphs.json"), graphs.Graphs); err != nil {
+		return err
+	}
+
+	if err := writeJSONFile(filepath.Join(output, dumpManifestFile), manifest); err != nil {
+		return err
+	}
+	fmt.Printf("Dumped %d collections, %d analyzers, %d views and %d graphs of '%s' to %s\n",
+		len(manifest.Collections), len(custom), len(viewProperties), len(graphs.Graphs), s.CurrentDB, output)
+	return nil
+}
+
+// dumpCollectionData writes all documents of a collection as JSONL.
+func (s *ShellContext) dumpCollectionData(path, collection string, batchSize int) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	ctx := driver.WithQueryBatchSize(s.transactionContext(s.Context), batchSize)
+	cursor, err := s.DB.Query(ctx, "FOR doc IN @@collection RETURN doc", map[string]interface{}{"@collection": collection})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	// Documents are written as the server sent them, so numbers keep their
+	// exact value
+	buffered := bufio.NewWriter(file)
+	var count int64
+	for {
+		var doc json.RawMessage
+		_, err := cursor.ReadDocument(ctx, &doc)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return count, err
+		}
+		if _, err := buffered.Write(append(doc, '\n')); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := buffered.Flush(); err != nil {
+		return count, err
+	}
+	return count, file.Close()
+}
+
+// restoreOptions holds the flags of the restore command.
+type restoreOptions struct {
+	input          string
+	filter         collectionFilter
+	targetDatabase string
+	createDatabase bool
+	overwrite      bool
+	batchSize      int
+	workers        int
+}
+
+// newRestoreCmd builds the restore command. The same command backs
+// `arango-cli restore` and the shell's /restore command.
+func newRestoreCmd(use string, session sessionFunc) *cobra.Command {
+	var o restoreOptions
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Restore a dump directory into a database",
+		Long: `Restore a directory written by dump. Analyzers, collections with their
+documents and indexes, views and graphs are created in the current database,
+or in --target-database. Use --config to restore into the database of a saved
+configuration.
+
+Existing collections, views and graphs are not touched unless --overwrite is
+given, in which case they are dropped and recreated.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			return s.restoreDatabase(o)
+		},
+	}
+	c.Flags().StringVarP(&o.input, "input", "i", "", "Dump directory to restore")
+	c.Flags().StringVar(&o.targetDatabase, "target-database", "", "Database to restore into (default: the current database)")
+	c.Flags().BoolVar(&o.createDatabase, "create-database", false, "Create the target database if it does not exist")
+	c.Flags().BoolVar(&o.overwrite, "overwrite", false, "Drop and recreate existing collections, views and graphs")
+	c.Flags().IntVar(&o.batchSize, "batch-size", defaultBatchSize, "Documents sent per request")
+	c.Flags().IntVar(&o.workers, "workers", 2, "Number of requests sent in parallel")
+	c.Flags().StringSliceVar(&o.filter.include, "collection", nil, "Only restore these collections (comma separated or repeated)")
+	c.Flags().StringSliceVar(&o.filter.exclude, "exclude-collection", nil, "Leave out these collections")
+	c.MarkFlagRequired("input")
+	return c
+}
+
+// restorableCollectionParameters are the collection properties that can be
+// passed when creating a collection.
+var restorableCollectionParameters = []string{
+	"type", "keyOptions", "numberOfShards", "replicationFactor", "writeConcern", "shardKeys",
+	"shardingStrategy", "waitForSync", "schema", "computedValues", "cacheEnabled", "isSystem",
+}
+
+func (s *ShellContext) restoreDatabase(o restoreOptions) error {
+	var manifest dumpManifest
+	if err := readJSONFile(filepath.Join(o.input, dumpManifestFile), &manifest); err != nil {
+		return fmt.Errorf("%s is not a dump directory: %v", o.input, err)
+	}
+
+	target, err := s.restoreTarget(o)
+	if err != nil {
+		return err
+	}
+	// Check for conflicts before anything is created, so a conflict does
+	// not leave a half restored database behind
+	if !o.overwrite {
+		if err := target.checkRestoreConflicts(manifest.Collections, o.filter); err != nil {
+			return err
+		}
+	}
+
+	var analyzers []map[string]interface{}
+	if err := readJSONFile(filepath.Join(o.input, "analyzers.json"), &analyzers); err != nil {
+		return err
+	}
+	analyzerCount := 0
+	for _, analyzer := range analyzers {
+		// Analyzer names are prefixed with the database they were dumped from
+		_, name, _ := strings.Cut(stringField(analyzer, "name"), "::")
+		analyzer["name"] = name
+		if err := target.apiRequest(s.Context, "POST", "_api/analyzer", analyzer, nil, http.StatusOK, http.StatusCreated); err != nil {
+			fmt.Printf("Warning: analyzer '%s' was not restored: %v\n", name, err)
+			continue
+		}
+		analyzerCount++
+	}
+
+	restored := map[string]bool{}
+	var failed int64
+	for _, name := range manifest.Collections {
+		if !o.filter.matches(name) {
+			continue
+		}
+		var structure collectionStructure
+		if err := readJSONFile(filepath.Join(o.input, name+".structure.json"), &structure); err != nil {
+			return err
+		}
+		col, err := target.restoreCollection(name, structure, o.overwrite)
+		if err != nil {
+			return err
+		}
+
+		result, err := target.restoreCollectionData(col, filepath.Join(o.input, name+".data.jsonl"), o)
+		if err != nil {
+			return fmt.Errorf("failed to restore documents of '%s': %v", name, err)
+		}
+		for _, failure := range result.failures {
+			fmt.Printf("%s.data.jsonl line %d: %s\n", name, failure.line, failure.message)
+		}
+		failed += result.failed
+
+		indexes := 0
+		for _, index := range structure.Indexes {
+			if indexType := stringField(index, "type"); indexType == "primary" || indexType == "edge" {
+				continue
+			}
+			for _, attribute := range []string{"id", "selectivityEstimate", "figures", "isNewlyCreated"} {
+				delete(index, attribute)
+			}
+			if err := target.apiRequest(s.Context, "POST", "_api/index?collection="+url.QueryEscape(name), index, nil, http.StatusOK, http.StatusCreated); err != nil {
+				return fmt.Errorf("failed to restore index '%s' of '%s': %v", stringField(index, "name"), name, err)
+			}
+			indexes++
+		}
+		restored[name] = true
+		fmt.Printf("Restored %s: %d documents, %d indexes\n", name, result.created, indexes)
+	}
+
+	views, err := target.restoreViews(filepath.Join(o.input, "views.json"), o.overwrite)
+	if err != nil {
+		return err
+	}
+	graphs, err := target.restoreGraphs(filepath.Join(o.input, "graphs.json"), o.overwrite)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %d collections, %d analyzers, %d views and %d graphs from %s into '%s'\n",
+		len(restored), analyzerCount, views, graphs, o.input, target.CurrentDB)
+	if failed > 0 {
+		return fmt.Errorf("%d documents failed to restore", failed)
+	}
+	return nil
+}
+
+// restoreTarget returns a session for the database to restore into. The
+// session itself stays on its database.
+func (s *ShellContext) restoreTarget(o restoreOptions) (*ShellContext, error) {
+	target := *s
+	target.Transaction = ""
+	if o.targetDatabase == "" || o.targetDatabase == s.CurrentDB {
+		return &target, nil
+	}
+
+	exists, err := s.Client.DatabaseExists(s.Context, o.targetDatabase)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		target.DB, err = s.Client.Database(s.Context, o.targetDatabase)
+	} else if o.createDatabase {
+		target.DB, err = s.Client.CreateDatabase(s.Context, o.targetDatabase, nil)
+	} else {
+		return nil, fmt.Errorf("database '%s' does not exist, use --create-database to create it", o.targetDatabase)
+	}
+	if err != nil {
+		return nil, err
+	}
+	target.CurrentDB = o.targetDatabase
+	return &target, nil
+}
+
+// checkRestoreConflicts fails if one of the collections to restore exists
+// in the target database already.
+func (s *ShellContext) checkRestoreConflicts(collections []string, filter collectionFilter) error {
+	for _, name := range collections {
+		if !filter.matches(name) {
+			continue
+		}
+		exists, err := s.DB.CollectionExists(s.Context, name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("collection '%s' already exists, use --overwrite to replace it or --exclude-collection to skip it", name)
+		}
+	}
+	return nil
+}
+
+// restoreCollection creates a collection from its dumped definition.
+func (s *ShellContext) restoreCollection(name string, structure collectionStructure, overwrite bool) (driver.Collection, error) {
+	exists, err := s.DB.CollectionExists(s.Context, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if !overwrite {
+			return nil, fmt.Errorf("collection '%s' already exists, use --overwrite to replace it or --exclude-collection to skip it", name)
+		}
+		col, err := s.DB.Collection(s.Context, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := col.Remove(s.Context); err != nil {
+			return nil, err
+		}
+	}
+
+	parameters := map[string]interface{}{"name": name}
+	for _, parameter := range restorableCollectionParameters {
+		if value, ok := structure.Parameters[parameter]; ok && value != nil {
+			parameters[parameter] = value
+		}
+	}
+	// The dumped documents keep their _key, which the server rejects unless
+	// user keys are allowed. Key options cannot be changed afterwards.
+	if keyOptions, ok := structure.Parameters["keyOptions"].(map[string]interface{}); ok {
+		options := map[string]interface{}{}
+		for option, value := range keyOptions {
+			options[option] = value
+		}
+		if allow, _ := options["allowUserKeys"].(bool); !allow {
+			fmt.Printf("Warning: collection '%s' did not allow user keys, it does after the restore\n", name)
+		}
+		options["allowUserKeys"] = true
+		delete(options, "lastValue")
+		parameters["keyOptions"] = options
+	}
+	if err := s.apiRequest(s.Context, "POST", "_api/collection", parameters, nil, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("failed to create collection '%s': %v", name, err)
+	}
+	return s.DB.Collection(s.Context, name)
+}
+
+// restoreCollectionData imports the documents of a collection with the same
+// machinery as the import command.
+func (s *ShellContext) restoreCollectionData(col driver.Collection, path string, o restoreOptions) (*importResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := &importResult{}
+	options := &driver.ImportDocumentOptions{OnDuplicate: driver.ImportOnDuplicateError}
+	importOpts := importOptions{batchSize: o.batchSize, workers: o.workers}
+	err = runImport(s.Context, col, newJSONLinesReader(file), options, importOpts, result)
+	return result, err
+}
+
+// restoreViews creates the dumped views. Links to collections that do not
+// exist in the target database are left out.
+func (s *ShellContext) restoreViews(path string, overwrite bool) (int, error) {
+	var views []map[string]interface{}
+	if err := readJSONFile(path, &views); err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, view := range views {
+		name := stringField(view, "name")
+		if err := s.apiRequest(s.Context, "GET", "_api/view/"+url.PathEscape(name), nil, nil, http.StatusOK); err == nil {
+			if !overwrite {
+				fmt.Printf("Warning: view '%s' already exists, use --overwrite to replace it\n", name)
+				continue
+			}
+			if err := s.apiRequest(s.Context, "DELETE", "_api/view/"+url.PathEscape(name), nil, nil, http.StatusOK); err != nil {
+				return restored, err
+			}
+		}
+
+		delete(view, "id")
+		delete(view, "globallyUniqueId")
+		if links, ok := view["links"].(map[string]interface{}); ok {
+			for collection := range links {
+				if exists, _ := s.DB.CollectionExists(s.Context, collection); !exists {
+					delete(links, collection)
+				}
+			}
+		}
+		if indexes, ok := view["indexes"].([]interface{}); ok {
+			kept := []interface{}{}
+			for _, index := range indexes {
+				definition, _ := index.(map[string]interface{})
+				if exists, _ := s.DB.CollectionExists(s.Context, stringField(definition, "collection")); exists {
+					kept = append(kept, index)
+				}
+			}
+			view["indexes"] = kept
+		}
+
+		if err := s.apiRequest(s.Context, "POST", "_api/view", view, nil, http.StatusOK, http.StatusCreated); err != nil {
+			return restored, fmt.Errorf("failed to restore view '%s': %v", name, err)
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// restoreGraphs creates the dumped named graphs. Graphs that use a
+// collection which does not exist in the target database are skipped.
+func (s *ShellContext) restoreGraphs(path string, overwrite bool) (int, error) {
+	var graphs []map[string]interface{}
+	if err := readJSONFile(path, &graphs); err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, graph := range graphs {
+		name := stringField(graph, "name")
+		var collections []string
+		definitions, _ := graph["edgeDefinitions"].([]interface{})
+		for _, definition := range definitions {
+			definition, _ := definition.(map[string]interface{})
+			collections = append(collections, stringField(definition, "collection"))
+			collections = append(collections, stringList(definition, "from")...)
+			collections = append(collections, stringList(definition, "to")...)
+		}
+		collections = append(collections, stringList(graph, "orphanCollections")...)
+		var missing []string
+		for _, collection := range collections {
+			if exists, _ := s.DB.CollectionExists(s.Context, collection); !exists {
+				missing = append(missing, collection)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			fmt.Printf("Warning: graph '%s' was not restored, collections are missing: %s\n", name, strings.Join(missing, ", "))
+			continue
+		}
+
+		if exists, err := s.DB.GraphExists(s.Context, name); err != nil {
+			return restored, err
+		} else if exists {
+			if !overwrite {
+				fmt.Printf("Warning: graph '%s' already exists, use --overwrite to replace it\n", name)
+				continue
+			}
+			// Only the graph definition is dropped, the collections were restored above
+			if err := s.apiRequest(s.Context, "DELETE", "_api/gharial/"+url.PathEscape(name), nil, nil, http.StatusOK, http.StatusAccepted); err != nil {
+				return restored, err
+			}
+		}
+
+		body := map[string]interface{}{
+			"name":              name,
+			"edgeDefinitions":   graph["edgeDefinitions"],
+			"orphanCollections": graph["orphanCollections"],
+		}
+		options := map[string]interface{}{}
+		for _, option := range []string{"numberOfShards", "replicationFactor", "writeConcern", "smartGraphAttribute", "isDisjoint"} {
+			if value, ok := graph[option]; ok {
+				options[option] = value
+			}
+		}
+		if len(options) > 0 {
+			body["options"] = options
+		}
+		if smart, _ := graph["isSmart"].(bool); smart {
+			body["isSmart"] = true
+		}
+		if err := s.apiRequest(s.Context, "POST", "_api/gharial", body, nil, http.StatusCreated, http.StatusAccepted); err != nil {
+			return restored, fmt.Errorf("failed to restore graph '%s': %v", name, err)
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+func writeJSONFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func readJSONFile(path string, value interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+var (
+	dumpCmd    = newDumpCmd("dump", connectFromFlags)
+	restoreCmd = newRestoreCmd("restore", connectFromFlags)
+)
+
+func init() {
+	for _, c := range []*cobra.Command{dumpCmd, restoreCmd} {
+		rootCmd.AddCommand(c)
+
+		// Keep stdout clean for scripts, so no banner here
+		c.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+		addConnectionFlags(c.Flags())
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+)
+
+// editDocument opens a document as JSON in $EDITOR and replaces it with the
+// edited version. The revision that was opened is used as precondition, so a
+// concurrent change is reported instead of overwritten.
+func (s *ShellContext) editDocument(args []string) {
+	collection, key, rest, err := splitDocumentRef(args)
+	if err == nil && len(rest) > 0 {
+		err = fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	col, err := s.DB.Collection(s.Context, collection)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// The driver decodes numbers as float64, so the document is read through
+	// the API and decoded with json.Number. Large integers and number formats
+	// then survive the round trip through the editor.
+	ctx := s.transactionContext(s.Context)
+	headers := map[string]string{}
+	if s.Transaction != "" {
+		headers["x-arango-trx-id"] = string(s.Transaction)
+	}
+	var raw json.RawMessage
+	apiPath := path.Join("_api/document", url.PathEscape(collection), url.PathEscape(key))
+	if err := s.apiRequestWithHeaders(s.Context, "GET", apiPath, headers, nil, &raw, 200); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	var meta driver.DocumentMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	original, err := decodeJSONObject(raw)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	content, err := json.MarshalIndent(original, "", "  ")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	file, err := os.CreateTemp("", "arango-cli-"+collection+"-*.json")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(append(content, '\n'))
+	file.Close()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	edited, ok := editJSONFile(file.Name())
+	if !ok {
+		return
+	}
+	if edited["_key"] != original["_key"] || edited["_id"] != original["_id"] {
+		fmt.Println("Error: _key and _id cannot be changed, the document was not saved")
+		return
+	}
+
+	changes := diffDocuments("", original, edited)
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	fmt.Printf("Changes to %s:\n", meta.ID)
+	for _, change := range changes {
+		fmt.Println("  " + change)
+	}
+	if !confirm(fmt.Sprintf("Replace %s?", meta.ID)) {
+		fmt.Println("Cancelled")
+		return
+	}
+
+	newMeta, err := col.ReplaceDocument(driver.WithRevision(ctx, meta.Rev), key, edited)
+	if driver.IsPreconditionFailed(err) {
+		fmt.Printf("Error: %s was changed by someone else since revision %s, your edit was not saved\n", meta.ID, meta.Rev)
+		return
+	} else if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Replaced %s (revision %s -> %s)\n", meta.ID, meta.Rev, newMeta.Rev)
+}
+
+// editJSONFile opens path in the user's editor until it contains a valid JSON
+// object or the user gives up.
+func editJSONFile(path string) (map[string]interface{}, bool) {
+	for {
+		if err := runEditor(path); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return nil, false
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return nil, false
+		}
+
+		doc, err := decodeJSONObject(data)
+		if err == nil {
+			return doc, true
+		}
+		fmt.Printf("Invalid JSON: %v\n", err)
+		if !confirm("Edit again?") {
+			fmt.Println("Cancelled")
+			return nil, false
+		}
+	}
+}
+
+// runEditor runs $VISUAL or $EDITOR, falling back to vi, on path. The editor
+// command may contain arguments, e.g. "code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %v", editor, err)
+	}
+	return nil
+}
+
+// diffDocuments lists the attributes that differ between two documents,
+// descending into nested objects. Lines start with + for added, - for removed
+// and ~ for changed attributes.
+func diffDocuments(prefix string, before, after map[string]interface{}) []string {
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, key := range sorted {
+		path := prefix + key
+		oldValue, inBefore := before[key]
+		newValue, inAfter := after[key]
+		switch {
+		case !inBefore:
+			changes = append(changes, fmt.Sprintf("+ %s: %s", path, diffValue(newValue)))
+		case !inAfter:
+			changes = append(changes, fmt.Sprintf("- %s: %s", path, diffValue(oldValue)))
+		case reflect.DeepEqual(oldValue, newValue):
+		default:
+			oldObject, oldIsObject := oldValue.(map[string]interface{})
+			newObject, newIsObject := newValue.(map[string]interface{})
+			if oldIsObject && newIsObject {
+				changes = append(changes, diffDocuments(path+".", oldObject, newObject)...)
+			} else {
+				changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", path, diffValue(oldValue), diffValue(newValue)))
+			}
+		}
+	}
+	return changes
+}
+
+func diffValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
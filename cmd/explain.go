@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+)
+
+// executionPlan is the part of an explain or profile response that is rendered.
+type executionPlan struct {
+	Nodes            []map[string]interface{} `json:"nodes"`
+	Rules            []string                 `json:"rules"`
+	EstimatedCost    float64                  `json:"estimatedCost"`
+	EstimatedNrItems int                      `json:"estimatedNrItems"`
+}
+
+// nodeStats holds the runtime statistics of one execution node from a profile.
+type nodeStats struct {
+	ID      int     `json:"id"`
+	Calls   int64   `json:"calls"`
+	Items   int64   `json:"items"`
+	Runtime float64 `json:"runtime"`
+}
+
+// profileResponse is the "extra" attribute of a cursor executed with profile level 2.
+type profileResponse struct {
+	Extra struct {
+		Plan  executionPlan `json:"plan"`
+		Stats struct {
+			ExecutionTime   float64     `json:"executionTime"`
+			PeakMemoryUsage int64       `json:"peakMemoryUsage"`
+			ScannedFull     int64       `json:"scannedFull"`
+			ScannedIndex    int64       `json:"scannedIndex"`
+			Filtered        int64       `json:"filtered"`
+			WritesExecuted  int64       `json:"writesExecuted"`
+			Nodes           []nodeStats `json:"nodes"`
+		} `json:"stats"`
+		Profile  map[string]float64 `json:"profile"`
+		Warnings []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"warnings"`
+	} `json:"extra"`
+}
+
+// explainQuery asks the optimizer for the execution plan of query and renders it.
+func (s *ShellContext) explainQuery(query string, bindVars map[string]interface{}) (string, error) {
+	options := &driver.ExplainQueryOptions{
+		Optimizer: driver.ExplainQueryOptimizerOptions{Rules: s.Settings.OptimizerRules},
+	}
+	result, err := s.DB.ExplainQuery(s.Context, normalizeQuery(query), bindVars, options)
+	if err != nil {
+		return "", err
+	}
+
+	plan := executionPlan{
+		Rules:            result.Plan.Rules,
+		EstimatedCost:    result.Plan.EstimatedCost,
+		EstimatedNrItems: result.Plan.EstimatedNrItems,
+	}
+	for _, node := range result.Plan.NodesRaw {
+		plan.Nodes = append(plan.Nodes, map[string]interface{}(node))
+	}
+
+	var sb strings.Builder
+	renderPlan(&sb, plan, nil)
+
+	sb.WriteString("\nOptimizer statistics:\n")
+	sb.WriteString(fmt.Sprintf("  Plans created: %d\n", result.Stats.PlansCreated))
+	sb.WriteString(fmt.Sprintf("  Rules executed: %d, skipped: %d\n", result.Stats.RulesExecuted, result.Stats.RulesSkipped))
+	if result.Cacheable != nil {
+		sb.WriteString(fmt.Sprintf("  Cacheable: %t\n", *result.Cacheable))
+	}
+	renderWarnings(&sb, result.Warnings)
+
+	return sb.String(), nil
+}
+
+// profileQuery executes query with profiling enabled and renders the plan
+// together with the runtime of every execution node.
+func (s *ShellContext) profileQuery(ctx context.Context, query string, bindVars map[string]interface{}) (string, error) {
+	// The per-node statistics are not exposed by the driver, so they are read
+	// from the raw response of the request that created the cursor.
+	var raw []byte
+	queryCtx := driver.WithQueryProfile(driver.WithRawResponse(s.queryContext(ctx), &raw), 2)
+
+	cursor, err := s.DB.Query(queryCtx, normalizeQuery(query), bindVars)
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close()
+
+	count := 0
+	for {
+		var doc interface{}
+		_, err := cursor.ReadDocument(ctx, &doc)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("error reading result: %v", err)
+		}
+		count++
+	}
+
+	var response profileResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return "", fmt.Errorf("failed to parse profile: %v", err)
+	}
+	extra := response.Extra
+
+	runtimes := map[int]nodeStats{}
+	for _, stats := range extra.Stats.Nodes {
+		runtimes[stats.ID] = stats
+	}
+
+	var sb strings.Builder
+	renderPlan(&sb, extra.Plan, runtimes)
+
+	sb.WriteString("\nExecution statistics:\n")
+	sb.WriteString(fmt.Sprintf("  Results: %d\n", count))
+	sb.WriteString(fmt.Sprintf("  Execution time: %v\n", secondsToDuration(extra.Stats.ExecutionTime)))
+	sb.WriteString(fmt.Sprintf("  Peak memory usage: %s\n", formatBytes(int(extra.Stats.PeakMemoryUsage))))
+	sb.WriteString(fmt.Sprintf("  Documents scanned: %d full, %d via index\n", extra.Stats.ScannedFull, extra.Stats.ScannedIndex))
+	sb.WriteString(fmt.Sprintf("  Documents filtered: %d\n", extra.Stats.Filtered))
+	sb.WriteString(fmt.Sprintf("  Documents written: %d\n", extra.Stats.WritesExecuted))
+
+	if len(extra.Profile) > 0 {
+		phases := make([]string, 0, len(extra.Profile))
+		for phase := range extra.Profile {
+			phases = append(phases, phase)
+		}
+		sort.Slice(phases, func(i, j int) bool { return extra.Profile[phases[i]] > extra.Profile[phases[j]] })
+
+		sb.WriteString("\nQuery phases:\n")
+		for _, phase := range phases {
+			sb.WriteString(fmt.Sprintf("  %-24s %v\n", phase, secondsToDuration(extra.Profile[phase])))
+		}
+	}
+
+	var warnings []string
+	for _, warning := range extra.Warnings {
+		warnings = append(warnings, fmt.Sprintf("%d: %s", warning.Code, warning.Message))
+	}
+	renderWarnings(&sb, warnings)
+
+	return sb.String(), nil
+}
+
+// renderPlan writes the execution node tree, starting at the node nothing
+// depends on and descending through the dependencies. runtimes may be nil.
+func renderPlan(sb *strings.Builder, plan executionPlan, runtimes map[int]nodeStats) {
+	nodes := map[int]map[string]interface{}{}
+	dependedOn := map[int]bool{}
+	for _, node := range plan.Nodes {
+		id := intField(node, "id")
+		nodes[id] = node
+		for _, dep := range intList(node, "dependencies") {
+			dependedOn[dep] = true
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("Execution plan (estimated cost %.2f, estimated items %d):\n\n", plan.EstimatedCost, plan.EstimatedNrItems))
+
+	var walk func(id int, prefix string, last bool)
+	walk = func(id int, prefix string, last bool) {
+		node, ok := nodes[id]
+		if !ok {
+			return
+		}
+		branch, indent := "├─ ", "│  "
+		if last {
+			branch, indent = "└─ ", "   "
+		}
+
+		line := fmt.Sprintf("%s%s#%d %s", prefix, branch, id, stringField(node, "type"))
+		line += fmt.Sprintf("  cost %.2f, items %d", floatField(node, "estimatedCost"), intField(node, "estimatedNrItems"))
+		if runtimes != nil {
+			stats := runtimes[id]
+			line += fmt.Sprintf("  | calls %d, items %d, runtime %v", stats.Calls, stats.Items, secondsToDuration(stats.Runtime))
+		}
+		if comment := describeNode(node); comment != "" {
+			line += "  " + comment
+		}
+		sb.WriteString(line + "\n")
+
+		deps := intList(node, "dependencies")
+		for i, dep := range deps {
+			walk(dep, prefix+indent, i == len(deps)-1)
+		}
+	}
+
+	for _, node := range plan.Nodes {
+		if id := intField(node, "id"); !dependedOn[id] {
+			walk(id, "", true)
+		}
+	}
+
+	sb.WriteString("\nIndexes used:\n")
+	usedIndexes := 0
+	for _, node := range plan.Nodes {
+		indexes, _ := node["indexes"].([]interface{})
+		for _, index := range indexes {
+			index, ok := index.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usedIndexes++
+			sb.WriteString(fmt.Sprintf("  #%d %s: %s on %s [%s]", intField(node, "id"), stringField(index, "name"),
+				stringField(index, "type"), stringField(node, "collection"), strings.Join(stringList(index, "fields"), ", ")))
+			if unique, _ := index["unique"].(bool); unique {
+				sb.WriteString(" unique")
+			}
+			if sparse, _ := index["sparse"].(bool); sparse {
+				sb.WriteString(" sparse")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if usedIndexes == 0 {
+		sb.WriteString("  none\n")
+	}
+
+	sb.WriteString("\nOptimizer rules applied:\n")
+	if len(plan.Rules) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, rule := range plan.Rules {
+		sb.WriteString("  " + rule + "\n")
+	}
+}
+
+// describeNode returns a short AQL-like summary of what an execution node does.
+func describeNode(node map[string]interface{}) string {
+	out := variableName(node, "outVariable")
+	in := variableName(node, "inVariable")
+	collection := stringField(node, "collection")
+
+	switch stringField(node, "type") {
+	case "EnumerateCollectionNode":
+		return fmt.Sprintf("FOR %s IN %s", out, collection)
+	case "IndexNode":
+		return fmt.Sprintf("FOR %s IN %s /* index scan */", out, collection)
+	case "EnumerateListNode":
+		return fmt.Sprintf("FOR %s IN %s", out, in)
+	case "EnumerateViewNode":
+		return fmt.Sprintf("FOR %s IN %s SEARCH ...", out, stringField(node, "view"))
+	case "CalculationNode":
+		return fmt.Sprintf("LET %s = ...", out)
+	case "SubqueryNode", "SubqueryStartNode":
+		if out != "" {
+			return fmt.Sprintf("LET %s = ( /* subquery */ )", out)
+		}
+	case "FilterNode":
+		return fmt.Sprintf("FILTER %s", in)
+	case "ReturnNode":
+		return fmt.Sprintf("RETURN %s", in)
+	case "LimitNode":
+		return fmt.Sprintf("LIMIT %d, %d", intField(node, "offset"), intField(node, "limit"))
+	case "SortNode":
+		return "SORT ..."
+	case "CollectNode":
+		return "COLLECT ..."
+	case "TraversalNode", "ShortestPathNode", "EnumeratePathsNode":
+		return fmt.Sprintf("FOR %s IN ... GRAPH TRAVERSAL", variableName(node, "vertexOutVariable"))
+	case "InsertNode":
+		return fmt.Sprintf("INSERT %s INTO %s", in, collection)
+	case "UpdateNode":
+		return fmt.Sprintf("UPDATE ... IN %s", collection)
+	case "ReplaceNode":
+		return fmt.Sprintf("REPLACE ... IN %s", collection)
+	case "RemoveNode":
+		return fmt.Sprintf("REMOVE %s IN %s", in, collection)
+	case "UpsertNode":
+		return fmt.Sprintf("UPSERT ... IN %s", collection)
+	}
+	return ""
+}
+
+func renderWarnings(sb *strings.Builder, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	sb.WriteString("\nWarnings:\n")
+	for _, warning := range warnings {
+		sb.WriteString("  " + warning + "\n")
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func variableName(node map[string]interface{}, field string) string {
+	variable, _ := node[field].(map[string]interface{})
+	return stringField(variable, "name")
+}
+
+func stringField(m map[string]interface{}, field string) string {
+	value, _ := m[field].(string)
+	return value
+}
+
+func floatField(m map[string]interface{}, field string) float64 {
+	value, _ := m[field].(float64)
+	return value
+}
+
+func intField(m map[string]interface{}, field string) int {
+	return int(floatField(m, field))
+}
+
+func intList(m map[string]interface{}, field string) []int {
+	values, _ := m[field].([]interface{})
+	list := make([]int, 0, len(values))
+	for _, value := range values {
+		if number, ok := value.(float64); ok {
+			list = append(list, int(number))
+		}
+	}
+	return list
+}
+
+func stringList(m map[string]interface{}, field string) []string {
+	values, _ := m[field].([]interface{})
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		list = append(list, fmt.Sprintf("%v", value))
+	}
+	return list
+}
@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/cobra"
+)
+
+// exportOptions holds the flags of the export command.
+type exportOptions struct {
+	collection string
+	query      string
+	file       string
+	format     string
+	gzip       bool
+	include    []string
+	exclude    []string
+	batchSize  int
+	params     []string
+	paramsFile string
+}
+
+// newExportCmd builds the export command. The same command backs
+// `arango-cli export` and the shell's /export command.
+func newExportCmd(use string, session sessionFunc) *cobra.Command {
+	o := exportOptions{}
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Export a collection or query result to a JSON, JSONL or CSV file",
+		Long: `Export all documents of a collection, or the result of an AQL query, to a
+file. Documents are streamed from the cursor batch by batch, so exports of any
+size use little memory. The format is taken from the file extension (.json,
+.jsonl, .csv, optionally followed by .gz) unless --format is given. Use
+--file - to write to stdout.
+
+Nested attributes are flattened into dotted CSV columns such as address.city.
+Unless --include is given, the CSV columns are taken from the first batch.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("batch-size") {
+				o.batchSize = s.Settings.BatchSize
+			}
+			return s.exportDocuments(o)
+		},
+	}
+	c.Flags().StringVar(&o.collection, "collection", "", "Collection to export")
+	c.Flags().StringVar(&o.query, "query", "", "AQL query whose result is exported")
+	c.Flags().StringVarP(&o.file, "file", "f", "", "File to write, - for stdout")
+	c.Flags().StringVar(&o.format, "format", "", "File format: json, jsonl or csv (default: from the file extension)")
+	c.Flags().BoolVar(&o.gzip, "gzip", false, "Compress the file with gzip (default for .gz files)")
+	c.Flags().StringSliceVar(&o.include, "include", nil, "Only export these attributes, dots select nested attributes")
+	c.Flags().StringSliceVar(&o.exclude, "exclude", nil, "Leave out these attributes, e.g. _rev,password")
+	c.Flags().IntVar(&o.batchSize, "batch-size", defaultBatchSize, "Documents fetched per cursor batch")
+	c.Flags().StringArrayVar(&o.params, "param", nil, "Bind parameter of --query as name=value (repeatable)")
+	c.Flags().StringVar(&o.paramsFile, "params-file", "", "JSON file with bind parameters of --query")
+	c.MarkFlagRequired("file")
+	return c
+}
+
+func (s *ShellContext) exportDocuments(o exportOptions) error {
+	if (o.collection == "") == (o.query == "") {
+		return fmt.Errorf("give either --collection or --query")
+	}
+
+	name := o.file
+	if strings.HasSuffix(strings.ToLower(name), ".gz") {
+		o.gzip = true
+		name = name[:len(name)-len(".gz")]
+	}
+	format := strings.ToLower(o.format)
+	if format == "" {
+		format = formatFromExtension(name)
+	}
+	if format != "json" && format != "jsonl" && format != "csv" {
+		return fmt.Errorf("unknown export format '%s', use --format json, jsonl or csv", format)
+	}
+
+	query, bindVars := o.query, map[string]interface{}{}
+	var total int64
+	if o.collection != "" {
+		col, err := s.DB.Collection(s.Context, o.collection)
+		if err != nil {
+			return err
+		}
+		if total, err = col.Count(s.transactionContext(s.Context)); err != nil {
+			return err
+		}
+		query, bindVars = "FOR doc IN @@collection RETURN doc", map[string]interface{}{"@collection": col.Name()}
+	} else {
+		loaded, err := loadBindVars(o.paramsFile, o.params)
+		if err != nil {
+			return err
+		}
+		var missing []string
+		if bindVars, missing = selectBindVars(query, loaded); len(missing) > 0 {
+			return fmt.Errorf("missing bind parameters: @%s", strings.Join(missing, ", @"))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(s.Context)
+	defer cancel()
+	// The session settings apply, but the count is always requested for the
+	// progress bar and --batch-size overrides the batch size setting
+	queryCtx := driver.WithQueryBatchSize(driver.WithQueryCount(s.queryContext(ctx), true), o.batchSize)
+	cursor, err := s.DB.Query(queryCtx, normalizeQuery(query), bindVars)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	if total == 0 {
+		total = cursor.Count()
+	}
+
+	var out io.Writer = os.Stdout
+	if o.file != "-" {
+		file, err := os.Create(o.file)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+	var w io.Writer = buffered
+	var compressor *gzip.Writer
+	if o.gzip {
+		compressor = gzip.NewWriter(buffered)
+		w = compressor
+	}
+
+	var writer exportWriter
+	switch format {
+	case "json":
+		writer = &jsonExportWriter{w: w}
+	case "jsonl":
+		writer = &jsonLinesExportWriter{w: w}
+	case "csv":
+		writer = newCSVExportWriter(w, o.include, o.batchSize)
+	}
+
+	var written int64
+	run := func() error {
+		for {
+			// Documents are decoded with json.Number, so numbers are
+			// exported with their exact value
+			var raw json.RawMessage
+			_, err := cursor.ReadDocument(ctx, &raw)
+			if driver.IsNoMoreDocuments(err) {
+				return writer.close()
+			} else if err != nil {
+				return err
+			}
+			var doc interface{}
+			if raw != nil {
+				if err := decodeJSON(raw, &doc); err != nil {
+					return err
+				}
+			}
+			if object, ok := doc.(map[string]interface{}); ok {
+				doc = projectDocument(object, o.include, o.exclude)
+			}
+			if err := writer.write(doc); err != nil {
+				return err
+			}
+			atomic.AddInt64(&written, 1)
+		}
+	}
+
+	if o.file != "-" && isTerminal(os.Stdout) {
+		err = runWithProgress(fmt.Sprintf("Exporting to %s", filepath.Base(o.file)), cancel, run, func() progressState {
+			done := atomic.LoadInt64(&written)
+			status := fmt.Sprintf("%d documents written", done)
+			if total > 0 {
+				status = fmt.Sprintf("%d of %d documents written", done, total)
+			}
+			return progressState{done: done, total: total, status: status}
+		})
+	} else {
+		err = run()
+	}
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("export cancelled after %d documents", written)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.file != "-" {
+		fmt.Printf("Exported %d documents to %s\n", written, o.file)
+	}
+	if csvWriter, ok := writer.(*csvExportWriter); ok && len(csvWriter.dropped) > 0 {
+		fmt.Printf("Warning: attributes not in the CSV header were left out: %s (use --include to choose the columns)\n",
+			strings.Join(csvWriter.droppedColumns(), ", "))
+	}
+	return nil
+}
+
+// projectDocument applies the --include and --exclude attribute lists.
+func projectDocument(doc map[string]interface{}, include, exclude []string) map[string]interface{} {
+	if len(include) > 0 {
+		projected := map[string]interface{}{}
+		for _, attribute := range include {
+			path := strings.Split(attribute, ".")
+			if value, ok := getPath(doc, path); ok {
+				setPath(projected, path, value)
+			}
+		}
+		doc = projected
+	}
+	for _, attribute := range exclude {
+		deletePath(doc, strings.Split(attribute, "."))
+	}
+	return doc
+}
+
+func getPath(doc map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, name := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func deletePath(doc map[string]interface{}, path []string) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := doc[name].(map[string]interface{})
+		if !ok {
+			return
+		}
+		doc = child
+	}
+	delete(doc, path[len(path)-1])
+}
+
+// exportWriter writes the documents of an export one at a time.
+type exportWriter interface {
+	write(doc interface{}) error
+	close() error
+}
+
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonExportWriter) write(doc interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	separator := ",\n  "
+	if j.count == 0 {
+		separator = "[\n  "
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type jsonLinesExportWriter struct {
+	w io.Writer
+}
+
+func (j *jsonLinesExportWriter) write(doc interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(append(data, '\n'))
+	return err
+}
+
+func (j *jsonLinesExportWriter) close() error {
+	return nil
+}
+
+// csvExportWriter writes flattened documents as CSV rows. The header must be
+// written before the first row, so without an explicit column list the first
+// batch of documents is buffered to collect the columns.
+type csvExportWriter struct {
+	w         *csv.Writer
+	columns   []string
+	header    bool
+	pending   []map[string]interface{}
+	sampleLen int
+	dropped   map[string]bool
+}
+
+func newCSVExportWriter(w io.Writer, columns []string, sampleLen int) *csvExportWriter {
+	return &csvExportWriter{
+		w:         csv.NewWriter(w),
+		columns:   columns,
+		sampleLen: sampleLen,
+		dropped:   map[string]bool{},
+	}
+}
+
+func (c *csvExportWriter) write(doc interface{}) error {
+	record, ok := doc.(map[string]interface{})
+	if !ok {
+		record = map[string]interface{}{"value": doc}
+	}
+	record = flattenDocument(record)
+
+	if c.columns == nil {
+		c.pending = append(c.pending, record)
+		if len(c.pending) < c.sampleLen {
+			return nil
+		}
+		return c.flushPending()
+	}
+	return c.writeRow(record)
+}
+
+// flushPending derives the columns from the buffered documents and writes
+// the header and the buffered rows.
+func (c *csvExportWriter) flushPending() error {
+	if c.columns == nil {
+		seen := map[string]bool{}
+		c.columns = []string{}
+		for _, record := range c.pending {
+			for column := range record {
+				if !seen[column] {
+					seen[column] = true
+					c.columns = append(c.columns, column)
+				}
+			}
+		}
+		sortColumns(c.columns)
+	}
+	for _, record := range c.pending {
+		if err := c.writeRow(record); err != nil {
+			return err
+		}
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *csvExportWriter) writeRow(record map[string]interface{}) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(c.columns); err != nil {
+			return err
+		}
+	}
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		if value, ok := record[column]; ok {
+			row[i] = formatCell(value)
+			delete(record, column)
+		}
+	}
+	for column := range record {
+		c.dropped[column] = true
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvExportWriter) close() error {
+	if err := c.flushPending(); err != nil {
+		return err
+	}
+	if !c.header && len(c.columns) > 0 {
+		c.header = true
+		if err := c.w.Write(c.columns); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) droppedColumns() []string {
+	columns := make([]string, 0, len(c.dropped))
+	for column := range c.dropped {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+var exportCmd = newExportCmd("export", connectFromFlags)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	// Keep stdout clean for scripts, so no banner here
+	exportCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+	addConnectionFlags(exportCmd.Flags())
+}
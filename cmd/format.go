@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v2"
+)
+
+// ResultFormatter renders the documents returned by a query.
+type ResultFormatter interface {
+	Format(w io.Writer, results []interface{}) error
+}
+
+const defaultShellFormat = "pretty"
+
+var resultFormatters = map[string]ResultFormatter{
+	"table":    tableFormatter{},
+	"json":     jsonFormatter{},
+	"jsonl":    jsonLinesFormatter{},
+	"csv":      delimitedFormatter{comma: ','},
+	"tsv":      delimitedFormatter{comma: '\t'},
+	"yaml":     yamlFormatter{},
+	"markdown": markdownFormatter{},
+}
+
+// getFormatter looks up a formatter by name. The shell's "pretty" layout is
+// not a formatter, it is rendered by FormatQueryResult.
+func getFormatter(name string) (ResultFormatter, error) {
+	formatter, ok := resultFormatters[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s' (available: %s)", name, strings.Join(formatNames(), ", "))
+	}
+	return formatter, nil
+}
+
+func formatNames() []string {
+	names := make([]string, 0, len(resultFormatters))
+	for name := range resultFormatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(w io.Writer, results []interface{}) error {
+	if results == nil {
+		results = []interface{}{}
+	}
+	jsonBytes, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(jsonBytes))
+	return err
+}
+
+type jsonLinesFormatter struct{}
+
+func (jsonLinesFormatter) Format(w io.Writer, results []interface{}) error {
+	for _, item := range results {
+		jsonBytes, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, string(jsonBytes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) Format(w io.Writer, results []interface{}) error {
+	if results == nil {
+		results = []interface{}{}
+	}
+	yamlBytes, err := yaml.Marshal(yamlValue(results))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(yamlBytes)
+	return err
+}
+
+// yamlValue converts whole-number floats decoded from JSON back to integers so
+// they are not written in exponent notation.
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = yamlValue(item)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = yamlValue(item)
+		}
+		return converted
+	}
+	return value
+}
+
+type tableFormatter struct{}
+
+func (tableFormatter) Format(w io.Writer, results []interface{}) error {
+	columns, rows := tabulate(results, false)
+
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = lipgloss.Width(column)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	border := "+"
+	for _, width := range widths {
+		border += strings.Repeat("-", width+2) + "+"
+	}
+
+	writeRow := func(cells []string) {
+		line := "|"
+		for i, cell := range cells {
+			line += " " + cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell)) + " |"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintln(w, border)
+	writeRow(columns)
+	fmt.Fprintln(w, border)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	fmt.Fprintln(w, border)
+	_, err := fmt.Fprintf(w, "%d row(s)\n", len(rows))
+	return err
+}
+
+type markdownFormatter struct{}
+
+func (markdownFormatter) Format(w io.Writer, results []interface{}) error {
+	columns, rows := tabulate(results, false)
+
+	escape := func(cells []string) []string {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			cell = strings.ReplaceAll(cell, "|", "\\|")
+			escaped[i] = strings.ReplaceAll(cell, "\n", "<br>")
+		}
+		return escaped
+	}
+
+	fmt.Fprintf(w, "| %s |\n", strings.Join(escape(columns), " | "))
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escape(row), " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delimitedFormatter writes CSV or TSV, flattening nested objects into
+// dotted column names such as "address.city".
+type delimitedFormatter struct {
+	comma rune
+}
+
+func (f delimitedFormatter) Format(w io.Writer, results []interface{}) error {
+	columns, rows := tabulate(results, true)
+
+	writer := csv.NewWriter(w)
+	writer.Comma = f.comma
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// tabulate derives columns from the union of document keys and renders every
+// document as a row of cells. Results that are not objects end up in a single
+// "value" column.
+func tabulate(results []interface{}, flatten bool) ([]string, [][]string) {
+	records := make([]map[string]interface{}, len(results))
+	seen := map[string]bool{}
+	var columns []string
+
+	for i, item := range results {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			record = map[string]interface{}{"value": item}
+		} else if flatten {
+			record = flattenDocument(record)
+		}
+		records[i] = record
+
+		for key := range record {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sortColumns(columns)
+
+	rows := make([][]string, len(records))
+	for i, record := range records {
+		row := make([]string, len(columns))
+		for j, column := range columns {
+			if value, ok := record[column]; ok {
+				row[j] = formatCell(value)
+			}
+		}
+		rows[i] = row
+	}
+	return columns, rows
+}
+
+// sortColumns orders columns alphabetically with the system attributes first.
+func sortColumns(columns []string) {
+	rank := func(column string) int {
+		switch column {
+		case "_key":
+			return 0
+		case "_id":
+			return 1
+		case "_from":
+			return 2
+		case "_to":
+			return 3
+		case "_rev":
+			return 4
+		}
+		return 5
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		if rank(columns[i]) != rank(columns[j]) {
+			return rank(columns[i]) < rank(columns[j])
+		}
+		return columns[i] < columns[j]
+	})
+}
+
+// flattenDocument turns nested objects into dotted keys. Arrays are kept as
+// values and rendered as JSON.
+func flattenDocument(doc map[string]interface{}) map[string]interface{} {
+	flat := map[string]interface{}{}
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		nested, ok := value.(map[string]interface{})
+		if !ok || len(nested) == 0 {
+			flat[prefix] = value
+			return
+		}
+		for key, child := range nested {
+			walk(prefix+"."+key, child)
+		}
+	}
+	for key, value := range doc {
+		walk(key, value)
+	}
+	return flat
+}
+
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	default:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(jsonBytes)
+	}
+}
@@ -8,10 +8,55 @@ func printHelp() {
 	/collections, /col          List collections in current database
 	/databases, /db             List available databases
 	/use <database>             Switch to a different database
+	/format [name]              Show or set the result output format
+	/set @<name> <json-value>   Set a bind parameter (@@<name> for collections)
+	/unset @<name>              Remove a bind parameter
+	/params                     List bind parameters
+	/set <setting> <value>      Change a query setting (batchSize, maxRuntime, ...)
+	/unset <setting>            Reset a query setting to its default
+	/show settings, /settings   Show the query settings
+	/collection <subcommand>    create, drop, truncate, rename, properties, count, figures
+	/get <collection>/<key>     Show a document (also: /get <collection> <key>)
+	/insert <col> <json|@file>  Insert a document (--overwrite-mode, --return-new)
+	/update <doc> <json|@file>  Update a document (--rev, --return-new/old, --keep-null, --merge-objects)
+	/replace <doc> <json|@file> Replace a document (--rev, --return-new/old)
+	/delete <doc>               Delete a document (--rev, --return-old; also: /remove)
+	/edit <doc>                 Edit a document as JSON in $EDITOR and replace it
+	/import --collection <c> --file <f>  Import a JSON, JSONL or CSV file (see /import --help)
+	/export --collection <c> --file <f>  Export to JSON, JSONL or CSV, also --query '<aql>'
+	/dump --output <dir>        Dump the current database (collections, indexes, views, graphs)
+	/restore --input <dir>      Restore a dump, optionally --target-database <db>
+	/transfer export|import     Resumable copy of a collection to or from JSONL (--resume)
+	/indexes <collection>       List indexes with fields, selectivity and flags
+	/index <subcommand>         list, create --type <type> --fields <a,b>, drop
+	/begin --write <cols> ...   Begin a stream transaction (--read, --write, --exclusive)
+	/commit                     Commit the running transaction
+	/abort                      Abort the running transaction
+	/explain <aql>              Show the execution plan of a query
+	/profile <aql>              Run a query and show per-node runtimes
+	/tui                        Open the full-screen workbench (tree, editor, results)
+	/history [filter]           List executed queries with time, duration and status
+	/rerun [n]                  Run history entry n again (default: the last one)
 	exit, quit                  Exit the shell
 	help                        Display this help message
 
 	Any other input will be executed as an AQL query.
+	Press Ctrl-C while a query runs to cancel and kill it on the server.
+	Completion suggests collections and views after IN and INTO, attributes
+	after doc., functions, bind parameters, graphs after GRAPH, databases after
+	/use and configurations after /switch.
+	Input and results are colored in a terminal, set NO_COLOR=1 to turn it off.
+	Press Ctrl-R to search the history, again for older matches, Enter to run
+	the match, Esc to edit it and Ctrl-G to leave the search.
+	Press t in the result viewer for a table: ←/→ select a column, s sorts,
+	x hides, a shows all, < and > move it, f filters (e.g. age >= 30 && name ~ al).
+	Press v in the result viewer for a tree: ←/→ collapse and expand, Enter
+	toggles, 1-9 expand everything to that depth and 0 collapses all.
+	Press / in the result viewer to search (text or regex, Tab limits it to keys
+	or values), n and N jump between matches and Esc clears the search.
+	In the tree or table, Enter on a handle like users/123 (_id, _from, _to)
+	opens that document, e lists the edges of the document under the cursor
+	and Backspace goes back.
 	Example queries:
 	RETURN DOCUMENT("users/123")
 	FOR doc IN users RETURN doc
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/thakurankit7/arango-cli/aql"
+)
+
+// inputColor marks the input text for highlightWriter. go-prompt sets the
+// input text color right before writing the input and uses this color for
+// nothing else.
+const inputColor = prompt.Fuchsia
+
+// highlightWriter wraps the prompt's console writer and writes the input text
+// with AQL syntax highlighting instead of in a single color.
+type highlightWriter struct {
+	prompt.ConsoleWriter
+	theme theme
+	input bool
+}
+
+func newHighlightWriter(theme theme) *highlightWriter {
+	return &highlightWriter{ConsoleWriter: prompt.NewStdoutWriter(), theme: theme}
+}
+
+func (w *highlightWriter) SetColor(fg, bg prompt.Color, bold bool) {
+	w.input = fg == inputColor
+	if w.input {
+		fg = prompt.DefaultColor
+	}
+	w.ConsoleWriter.SetColor(fg, bg, bold)
+}
+
+func (w *highlightWriter) WriteStr(data string) {
+	if !w.input || strings.HasPrefix(strings.TrimSpace(data), "/") {
+		w.ConsoleWriter.WriteStr(data)
+		return
+	}
+	// Earlier lines of a multi-line statement decide whether this line
+	// starts inside a string or comment
+	context := ""
+	if isMultilineMode {
+		context = buffer.String()
+	}
+	// Like WriteStr, don't let the input smuggle in escape sequences
+	data = strings.ReplaceAll(data, "\x1b", "")
+	w.ConsoleWriter.WriteRawStr(highlightAQL(context, data, w.theme))
+}
+
+// highlightAQL colors the tokens of text, which continues the statement
+// started by context.
+func highlightAQL(context, text string, t theme) string {
+	tokens := aql.Tokenize(context + text)
+	var sb strings.Builder
+	for i, token := range tokens {
+		end := token.Offset + len(token.Text)
+		if end <= len(context) {
+			continue
+		}
+		part := token.Text[max(len(context)-token.Offset, 0):]
+
+		switch token.Kind {
+		case aql.Keyword:
+			sb.WriteString(t.Keyword.paint(part))
+		case aql.String:
+			sb.WriteString(t.String.paint(part))
+		case aql.Number:
+			sb.WriteString(t.Number.paint(part))
+		case aql.BindParam:
+			sb.WriteString(t.BindParam.paint(part))
+		case aql.Comment:
+			sb.WriteString(t.Comment.paint(part))
+		case aql.Identifier:
+			if isFunctionCall(tokens[i+1:]) {
+				sb.WriteString(t.Function.paint(part))
+			} else {
+				sb.WriteString(part)
+			}
+		default:
+			sb.WriteString(part)
+		}
+	}
+	return sb.String()
+}
+
+// isFunctionCall reports whether the tokens following an identifier start
+// with an opening parenthesis or, for user-defined functions, a namespace
+// separator.
+func isFunctionCall(rest []aql.Token) bool {
+	for _, token := range rest {
+		if token.Kind == aql.Whitespace {
+			continue
+		}
+		return token.Kind == aql.Operator && (token.Text == "(" || strings.HasPrefix(token.Text, ":"))
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// maxHistoryEntries is the number of statements kept in a history file.
+const maxHistoryEntries = 1000
+
+const (
+	historyOK        = "ok"
+	historyFailed    = "error"
+	historyCancelled = "cancelled"
+)
+
+// historyEntry is a statement executed in the shell, stored as one JSON line
+// of the history file of the profile.
+type historyEntry struct {
+	Time       time.Time `json:"time"`
+	Query      string    `json:"query"`
+	DurationMs int64     `json:"durationMs"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// queryHistory holds the statements executed with a profile. It also keeps
+// the state of a running Ctrl-R search.
+type queryHistory struct {
+	path    string
+	entries []historyEntry
+	search  historySearch
+	warned  bool
+}
+
+// historySearch is an incremental reverse search. The prompt buffer holds the
+// search term while it runs, the match is shown in the prompt prefix.
+type historySearch struct {
+	active bool
+	term   string
+	// match is the index of the matching entry, -1 if nothing matches
+	match int
+}
+
+// historyPath returns the history file of profile, next to the config file.
+func historyPath(cm *ConfigManager, profile string) string {
+	dir := "config"
+	if cm != nil {
+		dir = filepath.Dir(cm.configPath)
+	}
+	return filepath.Join(dir, "history", profile+".jsonl")
+}
+
+// loadHistory reads the history file of profile. A missing or damaged file
+// only costs the lost entries, so errors are reported as a warning.
+func loadHistory(cm *ConfigManager, profile string) *queryHistory {
+	h := &queryHistory{path: historyPath(cm, profile)}
+
+	file, err := os.Open(h.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: could not read history: %v\n", err)
+		}
+		return h
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Query == "" {
+			continue
+		}
+		h.entries = append(h.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("Warning: could not read history: %v\n", err)
+	}
+
+	// The file is only appended to while the shell runs, so it is trimmed
+	// here once it grew past the limit
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+		if err := h.rewrite(); err != nil {
+			fmt.Printf("Warning: could not trim history: %v\n", err)
+		}
+	}
+	return h
+}
+
+// add records an executed statement and appends it to the history file.
+func (h *queryHistory) add(entry historyEntry) {
+	if h == nil {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+
+	if err := h.append(entry); err != nil && !h.warned {
+		h.warned = true
+		fmt.Printf("Warning: could not save history: %v\n", err)
+	}
+}
+
+func (h *queryHistory) append(entry historyEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// Queries may contain sensitive values, so the history is private
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (h *queryHistory) rewrite() error {
+	var sb strings.Builder
+	for _, entry := range h.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// statements returns the recorded statements for the prompt's Up/Down history,
+// oldest first. They are terminated so that Enter runs a recalled statement.
+func (h *queryHistory) statements() []string {
+	if h == nil {
+		return nil
+	}
+	statements := make([]string, 0, len(h.entries))
+	for _, entry := range h.entries {
+		statement := entry.Query + ";"
+		if n := len(statements); n > 0 && statements[n-1] == statement {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// find returns the index of the newest entry before index before that
+// contains term, ignoring case, or -1.
+func (h *queryHistory) find(term string, before int) int {
+	term = strings.ToLower(term)
+	for i := before - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(h.entries[i].Query), term) {
+			return i
+		}
+	}
+	return -1
+}
+
+// keyBindings returns the prompt key bindings of the reverse search.
+func (h *queryHistory) keyBindings() []prompt.KeyBind {
+	// Typing and deleting edit the search term, the buffer has already been
+	// changed when the binding runs
+	refine := func(buf *prompt.Buffer) {
+		if h.search.active {
+			h.search.term = buf.Text()
+			h.search.match = h.find(h.search.term, len(h.entries))
+		}
+	}
+	// Leaving the search with a cursor key puts the match into the buffer for
+	// editing, as in bash
+	edit := func(buf *prompt.Buffer) {
+		if h.search.active {
+			h.search.active = false
+			if h.search.match >= 0 {
+				setBufferText(buf, h.entries[h.search.match].Query+";")
+			}
+		}
+	}
+	cancel := func(buf *prompt.Buffer) {
+		h.search.active = false
+	}
+
+	return []prompt.KeyBind{
+		{Key: prompt.ControlR, Fn: func(buf *prompt.Buffer) {
+			if !h.search.active {
+				h.search = historySearch{active: true, term: buf.Text()}
+				h.search.match = h.find(h.search.term, len(h.entries))
+				return
+			}
+			// Ctrl-R again steps to the next older match, skipping repeats of
+			// the current one
+			for older := h.search.match; older > 0; {
+				if older = h.find(h.search.term, older); older < 0 {
+					break
+				}
+				if h.entries[older].Query != h.entries[h.search.match].Query {
+					h.search.match = older
+					break
+				}
+			}
+		}},
+		{Key: prompt.NotDefined, Fn: refine},
+		{Key: prompt.Backspace, Fn: refine},
+		{Key: prompt.ControlH, Fn: refine},
+		{Key: prompt.Escape, Fn: edit},
+		{Key: prompt.Left, Fn: edit},
+		{Key: prompt.Right, Fn: edit},
+		{Key: prompt.ControlG, Fn: func(buf *prompt.Buffer) {
+			if h.search.active {
+				h.search.active = false
+				setBufferText(buf, "")
+			}
+		}},
+		{Key: prompt.ControlC, Fn: cancel},
+		{Key: prompt.Up, Fn: cancel},
+		{Key: prompt.Down, Fn: cancel},
+	}
+}
+
+// searchPrefix returns the prompt prefix while a search runs.
+func (h *queryHistory) searchPrefix() (string, bool) {
+	if h == nil || !h.search.active {
+		return "", false
+	}
+	if h.search.match < 0 {
+		return "(failing reverse-i-search) ", true
+	}
+	preview := []rune(strings.Join(strings.Fields(h.entries[h.search.match].Query), " "))
+	if len(preview) > 60 {
+		preview = append(preview[:57], []rune("...")...)
+	}
+	return fmt.Sprintf("(reverse-i-search: %s) ", string(preview)), true
+}
+
+// acceptSearch ends a running search and returns the matching statement.
+func (h *queryHistory) acceptSearch() (string, bool) {
+	if h == nil || !h.search.active {
+		return "", false
+	}
+	h.search.active = false
+	if h.search.match < 0 {
+		return "", false
+	}
+	return h.entries[h.search.match].Query, true
+}
+
+func setBufferText(buf *prompt.Buffer, text string) {
+	buf.Delete(len([]rune(buf.Document().TextAfterCursor())))
+	buf.DeleteBeforeCursor(len([]rune(buf.Document().TextBeforeCursor())))
+	buf.InsertText(text, false, true)
+}
+
+// showHistory lists the recorded statements containing filter, numbered for
+// /rerun.
+func (s *ShellContext) showHistory(filter string) {
+	const limit = 100
+	h := s.History
+	if h == nil || len(h.entries) == 0 {
+		fmt.Println("History is empty")
+		return
+	}
+
+	var matches []int
+	for i, entry := range h.entries {
+		if filter == "" || strings.Contains(strings.ToLower(entry.Query), strings.ToLower(filter)) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No history entries contain '%s'\n", filter)
+		return
+	}
+	if len(matches) > limit {
+		fmt.Printf("Showing the last %d of %d entries, add a filter to narrow them down\n", limit, len(matches))
+		matches = matches[len(matches)-limit:]
+	}
+
+	for _, i := range matches {
+		entry := h.entries[i]
+		status := entry.Status
+		if entry.Error != "" {
+			status += ": " + entry.Error
+		}
+		lines := strings.Split(entry.Query, "\n")
+		fmt.Printf("%5d  %s  %8s  %s\n", i+1, entry.Time.Local().Format("2006-01-02 15:04:05"),
+			time.Duration(entry.DurationMs)*time.Millisecond, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Printf("%38s%s\n", "", line)
+		}
+		if entry.Status != historyOK {
+			fmt.Printf("%38s-- %s\n", "", status)
+		}
+	}
+}
+
+// rerun executes history entry n again, or the last entry if n is empty.
+func (s *ShellContext) rerun(n string) {
+	h := s.History
+	if h == nil || len(h.entries) == 0 {
+		fmt.Println("History is empty")
+		return
+	}
+
+	index := len(h.entries)
+	if n != "" {
+		var err error
+		if index, err = strconv.Atoi(n); err != nil || index < 1 || index > len(h.entries) {
+			fmt.Printf("Usage: /rerun [n], where n is a number from /history (1-%d)\n", len(h.entries))
+			return
+		}
+	}
+
+	query := h.entries[index-1].Query
+	fmt.Println(query)
+	s.executeQuery(query)
+}
@@ -0,0 +1,607 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/cobra"
+)
+
+// importOptions holds the flags of the import command.
+type importOptions struct {
+	collection       string
+	file             string
+	format           string
+	onDuplicate      string
+	batchSize        int
+	workers          int
+	createCollection bool
+	edge             bool
+	mappings         []string
+	inferTypes       bool
+}
+
+// newImportCmd builds the import command. The same command backs
+// `arango-cli import` and the shell's /import command.
+func newImportCmd(use string, session sessionFunc) *cobra.Command {
+	o := importOptions{}
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Import documents from a JSON, JSONL or CSV file",
+		Long: `Import documents from a file into a collection. The format is taken from
+the file extension (.json, .jsonl, .ndjson, .csv, .tsv) unless --format is
+given. A .json file must contain an array of documents.
+
+CSV columns are mapped to attributes by their header. Use --map to rename a
+column, e.g. --map "E-Mail=email"; dots create nested attributes, e.g.
+--map "City=address.city", and an empty target skips the column.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			return s.importFile(o)
+		},
+	}
+	c.Flags().StringVar(&o.collection, "collection", "", "Collection to import into")
+	c.Flags().StringVarP(&o.file, "file", "f", "", "File to import")
+	c.Flags().StringVar(&o.format, "format", "", "File format: json, jsonl, csv or tsv (default: from the file extension)")
+	c.Flags().StringVar(&o.onDuplicate, "on-duplicate", "error", "What to do with documents whose _key exists: ignore, update, replace or error")
+	c.Flags().IntVar(&o.batchSize, "batch-size", defaultBatchSize, "Documents sent per request")
+	c.Flags().IntVar(&o.workers, "workers", 2, "Number of requests sent in parallel")
+	c.Flags().BoolVar(&o.createCollection, "create-collection", false, "Create the collection if it does not exist")
+	c.Flags().BoolVar(&o.edge, "edge", false, "Create an edge collection (with --create-collection)")
+	c.Flags().StringArrayVar(&o.mappings, "map", nil, "Map a CSV column to an attribute as column=attribute (repeatable)")
+	c.Flags().BoolVar(&o.inferTypes, "infer-types", true, "Convert CSV numbers, booleans and null, otherwise import all values as strings")
+	c.MarkFlagRequired("collection")
+	c.MarkFlagRequired("file")
+	return c
+}
+
+// importRecord is a document read from the import file with the line it
+// starts on.
+type importRecord struct {
+	line int
+	doc  map[string]interface{}
+}
+
+// recordError is a problem with a single record that is reported and
+// skipped, unlike other read errors which stop the import.
+type recordError struct {
+	line    int
+	message string
+}
+
+func (e *recordError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.message)
+}
+
+// recordReader reads the records of an import file one at a time and
+// returns io.EOF at the end.
+type recordReader interface {
+	next() (importRecord, error)
+}
+
+// importResult collects the outcome of an import. It is shared by the
+// workers.
+type importResult struct {
+	mu       sync.Mutex
+	read     int64
+	created  int64
+	updated  int64
+	ignored  int64
+	failed   int64
+	failures []recordError
+}
+
+func (r *importResult) fail(line int, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed++
+	r.failures = append(r.failures, recordError{line: line, message: message})
+}
+
+// importBatch is a batch of documents sent in one request, with the line of
+// every document for error reporting.
+type importBatch struct {
+	lines []int
+	docs  []interface{}
+}
+
+func (s *ShellContext) importFile(o importOptions) error {
+	onDuplicate := driver.ImportOnDuplicate(strings.ToLower(o.onDuplicate))
+	switch onDuplicate {
+	case driver.ImportOnDuplicateIgnore, driver.ImportOnDuplicateUpdate, driver.ImportOnDuplicateReplace, driver.ImportOnDuplicateError:
+	default:
+		return fmt.Errorf("unknown --on-duplicate '%s' (available: ignore, update, replace, error)", o.onDuplicate)
+	}
+	if o.batchSize <= 0 || o.workers <= 0 {
+		return fmt.Errorf("--batch-size and --workers must be positive")
+	}
+
+	file, err := os.Open(o.file)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	input := &countingReader{r: file}
+
+	format := strings.ToLower(o.format)
+	if format == "" {
+		format = formatFromExtension(o.file)
+	}
+	var reader recordReader
+	switch format {
+	case "json":
+		reader = newJSONArrayReader(input)
+	case "jsonl", "ndjson":
+		reader = newJSONLinesReader(input)
+	case "csv", "tsv":
+		comma := ','
+		if format == "tsv" {
+			comma = '\t'
+		}
+		if reader, err = newCSVReader(input, comma, o.mappings, o.inferTypes); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown import format '%s', use --format json, jsonl, csv or tsv", format)
+	}
+
+	col, err := s.importCollection(o)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(s.Context)
+	defer cancel()
+	result := &importResult{}
+	options := &driver.ImportDocumentOptions{OnDuplicate: onDuplicate}
+	run := func() error {
+		return runImport(ctx, col, reader, options, o, result)
+	}
+
+	if isTerminal(os.Stdout) {
+		err = runWithProgress(fmt.Sprintf("Importing %s into %s", filepath.Base(o.file), col.Name()), cancel, run,
+			func() progressState {
+				result.mu.Lock()
+				defer result.mu.Unlock()
+				return progressState{
+					done:  atomic.LoadInt64(&input.n),
+					total: info.Size(),
+					status: fmt.Sprintf("%d read, %d created, %d updated, %d ignored, %d failed",
+						result.read, result.created, result.updated, result.ignored, result.failed),
+				}
+			})
+	} else {
+		err = run()
+	}
+
+	printImportSummary(result)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("import cancelled")
+	}
+	if result.failed > 0 {
+		return fmt.Errorf("%d documents failed to import", result.failed)
+	}
+	return nil
+}
+
+// importCollection opens the target collection, creating it if asked to.
+func (s *ShellContext) importCollection(o importOptions) (driver.Collection, error) {
+	exists, err := s.DB.CollectionExists(s.Context, o.collection)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return s.DB.Collection(s.Context, o.collection)
+	}
+	if !o.createCollection {
+		return nil, fmt.Errorf("collection '%s' does not exist, use --create-collection to create it", o.collection)
+	}
+	options := &driver.CreateCollectionOptions{}
+	if o.edge {
+		options.Type = driver.CollectionTypeEdge
+	}
+	return s.DB.CreateCollection(s.Context, o.collection, options)
+}
+
+// runImport reads all records and sends them in batches to the workers.
+func runImport(ctx context.Context, col driver.Collection, reader recordReader, options *driver.ImportDocumentOptions,
+	o importOptions, result *importResult) error {
+	batches := make(chan importBatch, o.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				importBatchDocuments(ctx, col, batch, options, result)
+			}
+		}()
+	}
+
+	var (
+		batch   importBatch
+		readErr error
+	)
+	send := func() bool {
+		if len(batch.docs) == 0 {
+			return true
+		}
+		select {
+		case batches <- batch:
+			batch = importBatch{}
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for ctx.Err() == nil {
+		record, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if recErr, ok := err.(*recordError); ok {
+			result.fail(recErr.line, recErr.message)
+			continue
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+		result.mu.Lock()
+		result.read++
+		result.mu.Unlock()
+		batch.lines = append(batch.lines, record.line)
+		batch.docs = append(batch.docs, record.doc)
+		if len(batch.docs) >= o.batchSize && !send() {
+			break
+		}
+	}
+	if readErr == nil {
+		send()
+	}
+	close(batches)
+	wg.Wait()
+	return readErr
+}
+
+// importBatchDocuments imports one batch and maps the errors the server
+// reports by position in the batch back to lines of the file.
+func importBatchDocuments(ctx context.Context, col driver.Collection, batch importBatch, options *driver.ImportDocumentOptions, result *importResult) {
+	var details []string
+	stats, err := col.ImportDocuments(driver.WithImportDetails(ctx, &details), batch.docs, options)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		for _, line := range batch.lines {
+			result.fail(line, err.Error())
+		}
+		return
+	}
+
+	result.mu.Lock()
+	result.created += stats.Created
+	result.updated += stats.Updated
+	result.ignored += stats.Ignored
+	result.mu.Unlock()
+
+	for _, detail := range details {
+		// Details look like "at position 3: creating document failed with ..."
+		var position int
+		line := 0
+		if _, err := fmt.Sscanf(detail, "at position %d:", &position); err == nil && position < len(batch.lines) {
+			line = batch.lines[position]
+			detail = strings.TrimSpace(detail[strings.Index(detail, ":")+1:])
+		}
+		result.fail(line, detail)
+	}
+}
+
+func printImportSummary(result *importResult) {
+	sort.Slice(result.failures, func(i, j int) bool { return result.failures[i].line < result.failures[j].line })
+	for _, failure := range result.failures {
+		if failure.line > 0 {
+			fmt.Printf("line %d: %s\n", failure.line, failure.message)
+		} else {
+			fmt.Println(failure.message)
+		}
+	}
+	fmt.Printf("Read %d documents: %d created, %d updated, %d ignored, %d failed\n",
+		result.read, result.created, result.updated, result.ignored, result.failed)
+}
+
+func formatFromExtension(file string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// countingReader counts the bytes read, which drives the progress bar.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+type jsonLinesReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLinesReader(r io.Reader) *jsonLinesReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	return &jsonLinesReader{scanner: scanner}
+}
+
+func (r *jsonLinesReader) next() (importRecord, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+		doc, err := decodeJSONObject([]byte(text))
+		if err != nil {
+			return importRecord{}, &recordError{line: r.line, message: fmt.Sprintf("invalid JSON: %v", err)}
+		}
+		return importRecord{line: r.line, doc: doc}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return importRecord{}, err
+	}
+	return importRecord{}, io.EOF
+}
+
+// decodeJSONObject decodes a JSON object, keeping numbers as json.Number.
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := decodeJSON(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decodeJSON decodes a single JSON value into v, keeping numbers as
+// json.Number so that large integers are not rounded.
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// jsonArrayReader streams the documents of a JSON array. Lines are counted
+// while reading so that errors can point to the line a document starts on.
+type jsonArrayReader struct {
+	lines   *lineCounter
+	decoder *json.Decoder
+	started bool
+}
+
+func newJSONArrayReader(r io.Reader) *jsonArrayReader {
+	lines := &lineCounter{r: r}
+	return &jsonArrayReader{lines: lines, decoder: json.NewDecoder(lines)}
+}
+
+func (r *jsonArrayReader) next() (importRecord, error) {
+	if !r.started {
+		r.started = true
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return importRecord{}, io.EOF
+		}
+		if delim, ok := token.(json.Delim); err != nil || !ok || delim != '[' {
+			return importRecord{}, fmt.Errorf("a .json import file must contain an array of documents, use --format jsonl for one document per line")
+		}
+	}
+	if !r.decoder.More() {
+		return importRecord{}, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := r.decoder.Decode(&raw); err != nil {
+		return importRecord{}, fmt.Errorf("line %d: invalid JSON: %v", r.lines.lineAt(r.decoder.InputOffset()), err)
+	}
+	line := r.lines.lineAt(r.decoder.InputOffset() - int64(len(raw)))
+
+	doc, err := decodeJSONObject(raw)
+	if err != nil {
+		return importRecord{}, &recordError{line: line, message: "not a JSON object"}
+	}
+	return importRecord{line: line, doc: doc}, nil
+}
+
+// lineCounter records the offsets of the line breaks it reads, to translate
+// offsets reported by a json.Decoder into line numbers.
+type lineCounter struct {
+	r      io.Reader
+	offset int64
+	breaks []int64
+}
+
+func (l *lineCounter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			l.breaks = append(l.breaks, l.offset+int64(i))
+		}
+	}
+	l.offset += int64(n)
+	return n, err
+}
+
+func (l *lineCounter) lineAt(offset int64) int {
+	return 1 + sort.Search(len(l.breaks), func(i int) bool { return l.breaks[i] >= offset })
+}
+
+// csvReader turns the rows of a CSV file into documents using the header row
+// for attribute names.
+type csvReader struct {
+	reader     *csv.Reader
+	attributes [][]string
+	inferTypes bool
+}
+
+func newCSVReader(r io.Reader, comma rune, mappings []string, inferTypes bool) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the CSV file is empty")
+	} else if err != nil {
+		return nil, err
+	}
+
+	renames := map[string]string{}
+	for _, mapping := range mappings {
+		column, attribute, ok := strings.Cut(mapping, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --map '%s', expected column=attribute", mapping)
+		}
+		renames[column] = attribute
+	}
+
+	attributes := make([][]string, len(header))
+	for i, column := range header {
+		// Excel writes a byte order mark before the first column
+		column = strings.TrimPrefix(column, "\ufeff")
+		attribute := column
+		if renamed, ok := renames[column]; ok {
+			attribute = renamed
+			delete(renames, column)
+		}
+		if attribute != "" {
+			attributes[i] = strings.Split(attribute, ".")
+		}
+	}
+	for column := range renames {
+		return nil, fmt.Errorf("--map refers to column '%s' which is not in the CSV header", column)
+	}
+
+	return &csvReader{reader: reader, attributes: attributes, inferTypes: inferTypes}, nil
+}
+
+func (r *csvReader) next() (importRecord, error) {
+	row, err := r.reader.Read()
+	if err == io.EOF {
+		return importRecord{}, io.EOF
+	}
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		return importRecord{}, &recordError{line: parseErr.StartLine, message: parseErr.Err.Error()}
+	} else if err != nil {
+		return importRecord{}, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	if len(row) > len(r.attributes) {
+		return importRecord{}, &recordError{line: line, message: fmt.Sprintf("%d values but only %d columns in the header", len(row), len(r.attributes))}
+	}
+	doc := map[string]interface{}{}
+	for i, value := range row {
+		path := r.attributes[i]
+		if path == nil || value == "" {
+			continue
+		}
+		setPath(doc, path, r.convert(path, value))
+	}
+	return importRecord{line: line, doc: doc}, nil
+}
+
+// convert infers the type of a CSV value. System attributes stay strings and
+// numbers with leading zeros, such as zip codes, are not converted.
+func (r *csvReader) convert(path []string, value string) interface{} {
+	if !r.inferTypes || (len(path) == 1 && strings.HasPrefix(path[0], "_")) {
+		return value
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	// The sign doesn't matter for leading zeros, "-012" stays a string too
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return value
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	// ParseFloat also takes hex floats, "NaN" and "Inf", which aren't numbers
+	// in JSON, so only decimal forms are converted
+	if decimalPattern.MatchString(value) {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+	}
+	return value
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// setPath sets a nested attribute, creating the objects on the way.
+func setPath(doc map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := doc[name].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			doc[name] = child
+		}
+		doc = child
+	}
+	doc[path[len(path)-1]] = value
+}
+
+var importCmd = newImportCmd("import", connectFromFlags)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	// Keep stdout clean for scripts, so no banner here
+	importCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+	addConnectionFlags(importCmd.Flags())
+}
@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/cobra"
+)
+
+// indexTypes are the index types /index create accepts.
+var indexTypes = []string{"persistent", "ttl", "geo", "fulltext", "inverted", "zkd", "mdi", "vector"}
+
+// newIndexCmd builds the index management commands. The same tree backs
+// `arango-cli index` and the shell's /index command.
+func newIndexCmd(use string, session sessionFunc) *cobra.Command {
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Manage indexes",
+	}
+	c.AddCommand(
+		newIndexListCmd(session),
+		newIndexCreateCmd(session),
+		newIndexDropCmd(session),
+	)
+	return c
+}
+
+// indexDescription is one entry of the index API. The driver does not expose
+// the selectivity estimate, so indexes are listed through the API directly.
+type indexDescription struct {
+	ID                  string        `json:"id"`
+	Name                string        `json:"name"`
+	Type                string        `json:"type"`
+	Fields              []interface{} `json:"fields"`
+	Unique              bool          `json:"unique"`
+	Sparse              bool          `json:"sparse"`
+	SelectivityEstimate *float64      `json:"selectivityEstimate"`
+	ExpireAfter         *int          `json:"expireAfter"`
+	Params              *vectorInfo   `json:"params"`
+}
+
+type vectorInfo struct {
+	Metric    string `json:"metric"`
+	Dimension int    `json:"dimension"`
+}
+
+func newIndexListCmd(session sessionFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <collection>",
+		Short: "List the indexes of a collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+
+			var response struct {
+				Indexes []indexDescription `json:"indexes"`
+			}
+			apiPath := "_api/index?collection=" + url.QueryEscape(args[0])
+			if err := s.apiRequest(s.Context, "GET", apiPath, nil, &response, http.StatusOK); err != nil {
+				return err
+			}
+			printIndexes(args[0], response.Indexes)
+			return nil
+		},
+	}
+}
+
+func printIndexes(collection string, indexes []indexDescription) {
+	fmt.Printf("Indexes of '%s':\n", collection)
+	fmt.Printf("  %-24s %-12s %-32s %-12s %s\n", "NAME", "TYPE", "FIELDS", "SELECTIVITY", "FLAGS")
+	for _, index := range indexes {
+		fields := make([]string, 0, len(index.Fields))
+		for _, field := range index.Fields {
+			// Inverted index fields are objects with a name attribute
+			if object, ok := field.(map[string]interface{}); ok {
+				field = object["name"]
+			}
+			fields = append(fields, fmt.Sprintf("%v", field))
+		}
+
+		selectivity := "-"
+		if index.SelectivityEstimate != nil {
+			selectivity = fmt.Sprintf("%.2f%%", *index.SelectivityEstimate*100)
+		}
+
+		var flags []string
+		if index.Unique {
+			flags = append(flags, "unique")
+		}
+		if index.Sparse {
+			flags = append(flags, "sparse")
+		}
+		if index.ExpireAfter != nil {
+			flags = append(flags, fmt.Sprintf("expireAfter=%ds", *index.ExpireAfter))
+		}
+		if index.Params != nil && index.Params.Dimension > 0 {
+			flags = append(flags, fmt.Sprintf("%s, %d dimensions", index.Params.Metric, index.Params.Dimension))
+		}
+
+		fmt.Printf("  %-24s %-12s %-32s %-12s %s\n", index.Name, index.Type, strings.Join(fields, ", "), selectivity, strings.Join(flags, ", "))
+	}
+}
+
+// indexOptions holds the flags of /index create. Which of them apply depends
+// on the index type.
+type indexOptions struct {
+	indexType       string
+	name            string
+	fields          []string
+	unique          bool
+	sparse          bool
+	noDeduplicate   bool
+	noEstimates     bool
+	cacheEnabled    bool
+	storedValues    []string
+	inBackground    bool
+	expireAfter     int
+	geoJSON         bool
+	minLength       int
+	fieldValueTypes string
+	prefixFields    []string
+	analyzer        string
+	metric          string
+	dimension       int
+	nLists          int
+	defaultNProbe   int
+}
+
+func newIndexCreateCmd(session sessionFunc) *cobra.Command {
+	var o indexOptions
+	c := &cobra.Command{
+		Use:   "create <collection>",
+		Short: "Create an index on a collection",
+		Long: `Create an index on a collection. The type defaults to persistent, the
+indexed attributes are given with --fields, e.g.
+
+  index create users --fields email --unique --sparse
+  index create sessions --type ttl --fields createdAt --expire-after 3600
+  index create docs --type vector --fields embedding --dimension 768 --metric cosine --n-lists 100`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			if len(o.fields) == 0 {
+				return fmt.Errorf("--fields is required")
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+
+			var estimates *bool
+			if o.noEstimates {
+				estimates = new(bool)
+			}
+
+			var (
+				index   driver.Index
+				created bool
+			)
+			switch strings.ToLower(o.indexType) {
+			case "persistent":
+				index, created, err = col.EnsurePersistentIndex(s.Context, o.fields, &driver.EnsurePersistentIndexOptions{
+					Unique:        o.unique,
+					Sparse:        o.sparse,
+					NoDeduplicate: o.noDeduplicate,
+					InBackground:  o.inBackground,
+					Name:          o.name,
+					Estimates:     estimates,
+					CacheEnabled:  o.cacheEnabled,
+					StoredValues:  o.storedValues,
+				})
+			case "ttl":
+				if len(o.fields) != 1 || o.expireAfter < 0 {
+					return fmt.Errorf("a TTL index needs exactly one field and --expire-after")
+				}
+				index, created, err = col.EnsureTTLIndex(s.Context, o.fields[0], o.expireAfter, &driver.EnsureTTLIndexOptions{
+					InBackground: o.inBackground,
+					Name:         o.name,
+				})
+			case "geo":
+				index, created, err = col.EnsureGeoIndex(s.Context, o.fields, &driver.EnsureGeoIndexOptions{
+					GeoJSON:      o.geoJSON,
+					InBackground: o.inBackground,
+					Name:         o.name,
+				})
+			case "fulltext":
+				index, created, err = col.EnsureFullTextIndex(s.Context, o.fields, &driver.EnsureFullTextIndexOptions{
+					MinLength:    o.minLength,
+					InBackground: o.inBackground,
+					Name:         o.name,
+				})
+			case "inverted":
+				options := &driver.InvertedIndexOptions{
+					Name:         o.name,
+					InBackground: o.inBackground,
+					Analyzer:     o.analyzer,
+				}
+				for _, field := range o.fields {
+					options.Fields = append(options.Fields, driver.InvertedIndexField{Name: field})
+				}
+				index, created, err = col.EnsureInvertedIndex(s.Context, options)
+			case "zkd":
+				index, created, err = col.EnsureZKDIndex(s.Context, o.fields, &driver.EnsureZKDIndexOptions{
+					Unique:          o.unique,
+					InBackground:    o.inBackground,
+					Name:            o.name,
+					FieldValueTypes: o.fieldValueTypes,
+				})
+			case "mdi":
+				options := driver.EnsureMDIIndexOptions{
+					Unique:          o.unique,
+					InBackground:    o.inBackground,
+					Name:            o.name,
+					FieldValueTypes: o.fieldValueTypes,
+					Sparse:          o.sparse,
+					StoredValues:    o.storedValues,
+				}
+				if len(o.prefixFields) > 0 {
+					index, created, err = col.EnsureMDIPrefixedIndex(s.Context, o.fields, &driver.EnsureMDIPrefixedIndexOptions{
+						EnsureMDIIndexOptions: options,
+						PrefixFields:          o.prefixFields,
+					})
+				} else {
+					index, created, err = col.EnsureMDIIndex(s.Context, o.fields, &options)
+				}
+			case "vector":
+				return s.createVectorIndex(col.Name(), o)
+			default:
+				return fmt.Errorf("unknown index type '%s' (available: %s)", o.indexType, strings.Join(indexTypes, ", "))
+			}
+			if err != nil {
+				return err
+			}
+
+			if created {
+				fmt.Printf("Created %s index '%s' on '%s'\n", index.Type(), index.UserName(), col.Name())
+			} else {
+				fmt.Printf("An equal %s index '%s' already exists on '%s'\n", index.Type(), index.UserName(), col.Name())
+			}
+			return nil
+		},
+	}
+	c.Flags().StringVarP(&o.indexType, "type", "t", "persistent", "Index type: "+strings.Join(indexTypes, ", "))
+	c.Flags().StringVar(&o.name, "name", "", "Index name (generated by the server if empty)")
+	c.Flags().StringSliceVar(&o.fields, "fields", nil, "Comma separated attribute paths to index")
+	c.Flags().BoolVar(&o.unique, "unique", false, "Reject documents with duplicate values (persistent, zkd, mdi)")
+	c.Flags().BoolVar(&o.sparse, "sparse", false, "Leave out documents where an indexed attribute is null or missing")
+	c.Flags().BoolVar(&o.noDeduplicate, "no-deduplicate", false, "Index duplicate array values separately (persistent)")
+	c.Flags().BoolVar(&o.noEstimates, "no-estimates", false, "Do not maintain selectivity estimates (persistent)")
+	c.Flags().BoolVar(&o.cacheEnabled, "cache-enabled", false, "Enable the in-memory index cache (persistent)")
+	c.Flags().StringSliceVar(&o.storedValues, "stored-values", nil, "Additional attributes stored in the index (persistent, mdi)")
+	c.Flags().BoolVar(&o.inBackground, "in-background", false, "Build the index without locking the collection for writes")
+	c.Flags().IntVar(&o.expireAfter, "expire-after", -1, "Seconds after the indexed timestamp documents expire (ttl)")
+	c.Flags().BoolVar(&o.geoJSON, "geo-json", false, "Interpret a single field as GeoJSON [longitude, latitude] (geo)")
+	c.Flags().IntVar(&o.minLength, "min-length", 0, "Minimum length of indexed words (fulltext)")
+	c.Flags().StringVar(&o.fieldValueTypes, "field-value-types", "double", "Type of the indexed values (zkd, mdi)")
+	c.Flags().StringSliceVar(&o.prefixFields, "prefix-fields", nil, "Prefix attributes for a prefixed mdi index (mdi)")
+	c.Flags().StringVar(&o.analyzer, "analyzer", "", "Default analyzer of the fields (inverted)")
+	c.Flags().StringVar(&o.metric, "metric", "cosine", "Similarity metric: cosine, l2 or innerProduct (vector)")
+	c.Flags().IntVar(&o.dimension, "dimension", 0, "Number of vector dimensions (vector)")
+	c.Flags().IntVar(&o.nLists, "n-lists", 0, "Number of Voronoi cells used for training (vector)")
+	c.Flags().IntVar(&o.defaultNProbe, "default-n-probe", 0, "Cells searched per query unless overridden (vector)")
+	return c
+}
+
+// createVectorIndex creates a vector index through the index API, as the
+// driver does not support them yet.
+func (s *ShellContext) createVectorIndex(collection string, o indexOptions) error {
+	if len(o.fields) != 1 || o.dimension <= 0 || o.nLists <= 0 {
+		return fmt.Errorf("a vector index needs exactly one field, --dimension and --n-lists")
+	}
+
+	params := map[string]interface{}{
+		"metric":    o.metric,
+		"dimension": o.dimension,
+		"nLists":    o.nLists,
+	}
+	if o.defaultNProbe > 0 {
+		params["defaultNProbe"] = o.defaultNProbe
+	}
+	body := map[string]interface{}{
+		"type":         "vector",
+		"fields":       o.fields,
+		"params":       params,
+		"inBackground": o.inBackground,
+	}
+	if o.name != "" {
+		body["name"] = o.name
+	}
+	if o.sparse {
+		body["sparse"] = true
+	}
+
+	var result struct {
+		Name           string `json:"name"`
+		IsNewlyCreated bool   `json:"isNewlyCreated"`
+	}
+	apiPath := "_api/index?collection=" + url.QueryEscape(collection)
+	if err := s.apiRequest(s.Context, "POST", apiPath, body, &result, http.StatusOK, http.StatusCreated); err != nil {
+		return err
+	}
+	if result.IsNewlyCreated {
+		fmt.Printf("Created vector index '%s' on '%s'\n", result.Name, collection)
+	} else {
+		fmt.Printf("An equal vector index '%s' already exists on '%s'\n", result.Name, collection)
+	}
+	return nil
+}
+
+func newIndexDropCmd(session sessionFunc) *cobra.Command {
+	var yes bool
+	c := &cobra.Command{
+		Use:   "drop <collection> <index>",
+		Short: "Drop an index by name or ID",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			col, err := s.DB.Collection(s.Context, args[0])
+			if err != nil {
+				return err
+			}
+			// Accept both the name and the full ID such as users/12345
+			name := strings.TrimPrefix(args[1], col.Name()+"/")
+			index, err := col.Index(s.Context, name)
+			if err != nil {
+				return err
+			}
+			if !yes && !confirm(fmt.Sprintf("Drop %s index '%s' on '%s'?", index.Type(), index.UserName(), col.Name())) {
+				fmt.Println("Cancelled")
+				return nil
+			}
+			if err := index.Remove(s.Context); err != nil {
+				return err
+			}
+			fmt.Printf("Dropped index '%s' on '%s'\n", index.UserName(), col.Name())
+			return nil
+		},
+	}
+	c.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	return c
+}
+
+var indexCmd = newIndexCmd("index", connectFromFlags)
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+
+	// Keep stdout clean for scripts, so no banner here
+	indexCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+	addConnectionFlags(indexCmd.PersistentFlags())
+}
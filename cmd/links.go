@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// documentHandlePattern matches document handles like "users/123": a
+// collection name and a key made of the characters ArangoDB allows in keys.
+var documentHandlePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+/[a-zA-Z0-9_\-:.@()+,=;$!*'%]+$`)
+
+// documentHandle returns value as a document handle if it looks like one.
+func documentHandle(value interface{}) (string, bool) {
+	handle, ok := value.(string)
+	if !ok || !documentHandlePattern.MatchString(handle) {
+		return "", false
+	}
+	return handle, true
+}
+
+// documentID returns the _id of a document.
+func documentID(doc interface{}) (string, bool) {
+	if object, ok := doc.(map[string]interface{}); ok {
+		return documentHandle(object["_id"])
+	}
+	return "", false
+}
+
+// maxLinkedEdges caps the edges listed for a document, so a hub with many
+// connections does not fill the viewer.
+const maxLinkedEdges = 1000
+
+// linkMsg carries documents fetched for a followed link back into the viewer.
+type linkMsg struct {
+	title string
+	docs  []interface{}
+	// depth is how far the tree of the new view is expanded
+	depth int
+	err   error
+}
+
+// fetchDocument returns a command that reads the document behind handle.
+func (s *ShellContext) fetchDocument(handle string) tea.Cmd {
+	return func() tea.Msg {
+		collection, key, _ := strings.Cut(handle, "/")
+		col, err := s.DB.Collection(s.Context, collection)
+		if err != nil {
+			return linkMsg{err: fmt.Errorf("failed to open %s: %v", handle, err)}
+		}
+		var doc map[string]interface{}
+		if _, err := col.ReadDocument(s.transactionContext(s.Context), key, &doc); err != nil {
+			return linkMsg{err: fmt.Errorf("failed to read %s: %v", handle, err)}
+		}
+		return linkMsg{title: handle, docs: []interface{}{doc}, depth: 1}
+	}
+}
+
+// fetchEdges returns a command that reads the edges pointing to or from the
+// document handle in all edge collections of the current database,
+// outbound edges first and at most maxLinkedEdges of them.
+func (s *ShellContext) fetchEdges(handle string) tea.Cmd {
+	return func() tea.Msg {
+		var response struct {
+			Result []driver.CollectionInfo `json:"result"`
+		}
+		if err := s.apiRequest(s.Context, "GET", "_api/collection", nil, &response); err != nil {
+			return linkMsg{err: fmt.Errorf("failed to list collections: %v", err)}
+		}
+
+		bindVars := map[string]interface{}{"start": handle}
+		var collections []string
+		for _, info := range response.Result {
+			if info.Type != driver.CollectionTypeEdge || strings.HasPrefix(info.Name, "_") {
+				continue
+			}
+			param := fmt.Sprintf("edges%d", len(collections))
+			bindVars["@"+param] = info.Name
+			collections = append(collections, "@@"+param)
+		}
+		if len(collections) == 0 {
+			return linkMsg{err: fmt.Errorf("no edge collections in database '%s'", s.CurrentDB)}
+		}
+
+		// The counts cover all edges, the list stops at maxLinkedEdges
+		traversal := "FOR v, e IN 1 ANY @start " + strings.Join(collections, ", ")
+		query := "LET outbound = LENGTH((" + traversal + " FILTER e._from == @start RETURN 1))" +
+			" LET total = LENGTH((" + traversal + " RETURN 1))" +
+			" LET edges = (" + traversal + " SORT e._from == @start DESC, e._id LIMIT @limit RETURN e)" +
+			" RETURN {outbound, total, edges}"
+		bindVars["limit"] = maxLinkedEdges
+		var result struct {
+			Outbound int           `json:"outbound"`
+			Total    int           `json:"total"`
+			Edges    []interface{} `json:"edges"`
+		}
+		cursor, err := s.openCursor(s.Context, query, bindVars)
+		if err == nil {
+			_, err = cursor.ReadDocument(s.Context, &result)
+			cursor.Close()
+		}
+		if err != nil {
+			return linkMsg{err: fmt.Errorf("failed to read edges of %s: %v", handle, err)}
+		}
+
+		title := fmt.Sprintf("Edges of %s (%d outbound, %d inbound)", handle, result.Outbound, result.Total-result.Outbound)
+		if result.Total > len(result.Edges) {
+			title = fmt.Sprintf("Edges of %s (first %d of %d: %d outbound, %d inbound)",
+				handle, len(result.Edges), result.Total, result.Outbound, result.Total-result.Outbound)
+		}
+		return linkMsg{title: title, docs: result.Edges}
+	}
+}
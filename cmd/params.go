@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/thakurankit7/arango-cli/aql"
+)
+
+// bindParamName converts a parameter as written in AQL (@name, @@coll) into
+// its bind variable key.
+func bindParamName(name string) string {
+	return strings.TrimPrefix(name, "@")
+}
+
+// parseBindValue parses value as JSON and falls back to a plain string, so
+// that both `42` and `alice` are accepted. Numbers keep their exact value.
+func parseBindValue(value string) interface{} {
+	var parsed interface{}
+	if err := decodeJSON([]byte(value), &parsed); err != nil {
+		return value
+	}
+	return parsed
+}
+
+// selectBindVars picks the bind variables referenced by query. ArangoDB
+// rejects bind variables that are not used in the query.
+func selectBindVars(query string, bindVars map[string]interface{}) (map[string]interface{}, []string) {
+	selected := map[string]interface{}{}
+	var missing []string
+	for _, name := range aql.BindParams(query) {
+		if value, ok := bindVars[name]; ok {
+			selected[name] = value
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, missing
+	}
+	return selected, missing
+}
+
+// loadBindVars reads bind variables from a JSON params file and --param
+// name=value flags. Flags override values from the file.
+func loadBindVars(paramsFile string, params []string) (map[string]interface{}, error) {
+	bindVars := map[string]interface{}{}
+
+	if paramsFile != "" {
+		data, err := os.ReadFile(paramsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read params file: %v", err)
+		}
+		if err := decodeJSON(data, &bindVars); err != nil {
+			return nil, fmt.Errorf("params file must contain a JSON object: %v", err)
+		}
+	}
+
+	for _, param := range params {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --param '%s', expected name=value", param)
+		}
+		bindVars[bindParamName(name)] = parseBindValue(value)
+	}
+
+	return bindVars, nil
+}
+
+// readLine prints label and reads a single line from stdin.
+func readLine(label string) (string, error) {
+	fmt.Print(label)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (s *ShellContext) setParam(name, value string) {
+	if !strings.HasPrefix(name, "@") || value == "" {
+		fmt.Println("Usage: /set @<name> <json-value>")
+		return
+	}
+	s.BindVars[bindParamName(name)] = parseBindValue(value)
+	fmt.Printf("Bind parameter %s set\n", name)
+}
+
+func (s *ShellContext) unsetParam(name string) {
+	if name == "" {
+		fmt.Println("Usage: /unset @<name>")
+		return
+	}
+	key := bindParamName(name)
+	if _, ok := s.BindVars[key]; !ok {
+		fmt.Printf("Bind parameter %s is not set\n", name)
+		return
+	}
+	delete(s.BindVars, key)
+	fmt.Printf("Bind parameter %s removed\n", name)
+}
+
+func (s *ShellContext) showParams() {
+	if len(s.BindVars) == 0 {
+		fmt.Println("No bind parameters set. Use /set @<name> <json-value> to add one.")
+		return
+	}
+
+	names := make([]string, 0, len(s.BindVars))
+	for name := range s.BindVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Bind parameters:")
+	for _, name := range names {
+		value, _ := json.Marshal(s.BindVars[name])
+		fmt.Printf("  @%s = %s\n", name, value)
+	}
+}
+
+// resolveBindVars returns the bind variables for query, asking for the value
+// of every parameter that is still unbound. Answers are kept for the session.
+// It returns false if the user left a value empty to cancel the query.
+func (s *ShellContext) resolveBindVars(query string) (map[string]interface{}, bool) {
+	_, missing := selectBindVars(query, s.BindVars)
+	for _, name := range missing {
+		value, err := readLine(fmt.Sprintf("Value for @%s (JSON, empty to cancel): ", name))
+		if err != nil || value == "" {
+			fmt.Println("Query cancelled")
+			return nil, false
+		}
+		s.BindVars[name] = parseBindValue(value)
+	}
+
+	bindVars, _ := selectBindVars(query, s.BindVars)
+	return bindVars, true
+}
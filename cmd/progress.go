@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressState is a snapshot of a long running operation. total is 0 if the
+// amount of work is unknown.
+type progressState struct {
+	done   int64
+	total  int64
+	status string
+}
+
+type progressTickMsg struct{}
+
+type progressDoneMsg struct {
+	err error
+}
+
+// progressModel shows a progress bar for an operation running in the
+// background, refreshed from its state function a few times per second.
+type progressModel struct {
+	title  string
+	bar    progress.Model
+	state  func() progressState
+	cancel func()
+	done   bool
+}
+
+func progressTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return progressTickMsg{} })
+}
+
+func (m progressModel) Init() tea.Cmd {
+	return progressTick()
+}
+
+func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.cancel()
+			return m, nil
+		}
+	case progressTickMsg:
+		return m, progressTick()
+	case progressDoneMsg:
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m progressModel) View() string {
+	state := m.state()
+	percent := 0.0
+	if state.total > 0 {
+		percent = min64(float64(state.done)/float64(state.total), 1)
+	}
+	view := fmt.Sprintf("%s\n%s\n%s\n", m.title, m.bar.ViewAs(percent), state.status)
+	if !m.done {
+		view += "Press Ctrl-C to cancel\n"
+	}
+	return view
+}
+
+func min64(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// runWithProgress runs fn while showing a progress bar. Ctrl-C calls cancel,
+// fn is expected to return soon after.
+func runWithProgress(title string, cancel func(), fn func() error, state func() progressState) error {
+	m := progressModel{
+		title:  title,
+		bar:    progress.New(progress.WithDefaultGradient(), progress.WithWidth(60)),
+		state:  state,
+		cancel: cancel,
+	}
+	prog := tea.NewProgram(m)
+
+	result := make(chan error, 1)
+	go func() {
+		err := fn()
+		result <- err
+		prog.Send(progressDoneMsg{err: err})
+	}()
+
+	if _, err := prog.Run(); err != nil {
+		cancel()
+		<-result
+		return err
+	}
+	return <-result
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thakurankit7/arango-cli/aql"
+)
+
+var (
+	queryExpr    string
+	queryFile    string
+	queryFormat  string
+	queryParams  []string
+	paramsFile   string
+	queryExplain bool
+	queryProfile bool
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Execute AQL without starting the interactive shell",
+	Long: `Execute one or more AQL statements and write the results to stdout.
+
+The AQL is read from --execute, from --file, or from stdin when it is piped in.
+Multiple statements are separated by semicolons.`,
+	Example: `  arango-cli query -c local -e 'FOR u IN users RETURN u'
+  arango-cli query -c local --file migrate.aql
+  echo 'RETURN LENGTH(users)' | arango-cli query -c local
+  arango-cli query -c local -e 'FOR d IN @@coll FILTER d.age > @age RETURN d' --param @@coll=users --param age=30`,
+	// Keep stdout clean for scripts, so no banner here
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if queryExplain && queryProfile {
+			return fmt.Errorf("--explain and --profile cannot be used together")
+		}
+
+		formatter, err := getFormatter(queryFormat)
+		if err != nil {
+			return err
+		}
+
+		input, err := readQueryInput()
+		if err != nil {
+			return err
+		}
+
+		bindVars, err := loadBindVars(paramsFile, queryParams)
+		if err != nil {
+			return err
+		}
+
+		shellCtx, err := connectFromFlags()
+		if err != nil {
+			return fmt.Errorf("failed to connect: %v", err)
+		}
+		if cmd.Flags().Changed("batch-size") {
+			shellCtx.Settings.BatchSize = batchSize
+		}
+
+		for _, statement := range aql.Split(input) {
+			if err := runStatement(shellCtx, statement, bindVars, formatter); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// runStatement executes a single statement and writes its output to stdout.
+// Ctrl-C cancels the statement and kills it on the server.
+func runStatement(shellCtx *ShellContext, statement string, bindVars map[string]interface{}, formatter ResultFormatter) error {
+	selected, missing := selectBindVars(statement, bindVars)
+	if len(missing) > 0 {
+		return fmt.Errorf("missing value for bind parameter @%s", missing[0])
+	}
+
+	run := shellCtx.startInterruptible()
+	defer run.stop()
+
+	var (
+		resultData []interface{}
+		rendered   string
+		err        error
+	)
+	switch {
+	case queryProfile:
+		rendered, err = shellCtx.profileQuery(run.ctx, statement, selected)
+	case queryExplain:
+		rendered, err = shellCtx.explainQuery(statement, selected)
+	default:
+		resultData, _, err = shellCtx.runQuery(run.ctx, statement, selected)
+	}
+	if err != nil {
+		if run.wasInterrupted() {
+			return errors.New(shellCtx.killQuery(normalizeQuery(statement), run.started))
+		}
+		return err
+	}
+
+	if queryExplain || queryProfile {
+		_, err = fmt.Fprintln(os.Stdout, rendered)
+		return err
+	}
+	return formatter.Format(os.Stdout, resultData)
+}
+
+// readQueryInput returns the AQL given through --execute, --file or stdin.
+func readQueryInput() (string, error) {
+	if queryExpr != "" && queryFile != "" {
+		return "", fmt.Errorf("--execute and --file cannot be used together")
+	}
+	if queryExpr != "" {
+		return queryExpr, nil
+	}
+	if queryFile != "" {
+		data, err := os.ReadFile(queryFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read query file: %v", err)
+		}
+		return string(data), nil
+	}
+
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return "", err
+	}
+	if stat.Mode()&os.ModeCharDevice != 0 {
+		return "", fmt.Errorf("no query given: use --execute, --file or pipe AQL to stdin")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %v", err)
+	}
+	return string(data), nil
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	addConnectionFlags(queryCmd.Flags())
+	queryCmd.Flags().StringVarP(&queryExpr, "execute", "e", "", "AQL to execute")
+	queryCmd.Flags().StringVarP(&queryFile, "file", "f", "", "File containing AQL to execute")
+	queryCmd.Flags().StringArrayVar(&queryParams, "param", nil, "Bind parameter as name=value, value is parsed as JSON (repeatable)")
+	queryCmd.Flags().StringVar(&paramsFile, "params-file", "", "JSON file with bind parameters")
+	queryCmd.Flags().BoolVar(&queryExplain, "explain", false, "Print the execution plan instead of running the query")
+	queryCmd.Flags().BoolVar(&queryProfile, "profile", false, "Run the query and print the plan with per-node runtimes")
+	queryCmd.Flags().IntVar(&batchSize, "batch-size", defaultBatchSize, "Number of documents fetched per cursor batch")
+	queryCmd.Flags().StringVarP(&queryFormat, "format", "o", "json", "Output format: "+strings.Join(formatNames(), ", "))
+}
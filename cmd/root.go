@@ -9,6 +9,10 @@ var rootCmd = &cobra.Command{
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		PrintBanner()
 	},
+	// main prints the error, and cobra only honours these on the executed
+	// command or here, not on the parents of subcommands
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func Execute() error {
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchScope restricts a search to the keys or the values of JSON results.
+type searchScope int
+
+const (
+	searchAll searchScope = iota
+	searchKeys
+	searchValues
+)
+
+func (s searchScope) String() string {
+	switch s {
+	case searchKeys:
+		return "keys"
+	case searchValues:
+		return "values"
+	}
+	return "all"
+}
+
+var (
+	searchMatchStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220"))
+	searchCurrentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("205")).Bold(true)
+
+	// jsonKeyPattern finds the key of a line of indented JSON
+	jsonKeyPattern = regexp.MustCompile(`^\s*"(?:[^"\\]|\\.)*"\s*:`)
+)
+
+// searchMatch is a match in a line of the wrapped viewer text, as byte
+// offsets into the line without colors.
+type searchMatch struct {
+	line  int
+	start int
+	end   int
+}
+
+// viewerSearch is the search of the result viewer's text mode, started with /.
+type viewerSearch struct {
+	input   textinput.Model
+	editing bool
+	scope   searchScope
+	regex   *regexp.Regexp
+	err     error
+
+	matches []searchMatch
+	current int
+}
+
+func newViewerSearch() *viewerSearch {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "text or regex, Tab: keys/values"
+	return &viewerSearch{input: input}
+}
+
+// start opens the input with the previous term.
+func (s *viewerSearch) start() tea.Cmd {
+	s.editing = true
+	s.input.CursorEnd()
+	return s.input.Focus()
+}
+
+// compile updates the regular expression from the input. The search ignores
+// case unless the term contains upper case letters.
+func (s *viewerSearch) compile() {
+	term := s.input.Value()
+	s.regex, s.err = nil, nil
+	if term == "" {
+		return
+	}
+	if strings.ToLower(term) == term {
+		term = "(?i)" + term
+	}
+	s.regex, s.err = regexp.Compile(term)
+}
+
+// update handles a key while the search term is typed. The matches follow
+// the term as it is typed; Esc drops the search.
+func (s *viewerSearch) update(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		s.editing = false
+		s.input.Blur()
+		return nil
+	case "esc":
+		s.editing = false
+		s.input.Blur()
+		s.input.SetValue("")
+	case "tab":
+		s.scope = (s.scope + 1) % 3
+		s.input.Prompt = "/"
+		if s.scope != searchAll {
+			s.input.Prompt = fmt.Sprintf("(%s) /", s.scope)
+		}
+	default:
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		s.compile()
+		s.current = 0
+		return cmd
+	}
+	s.compile()
+	return nil
+}
+
+// active reports whether there is a term to highlight.
+func (s *viewerSearch) active() bool {
+	return s != nil && s.regex != nil
+}
+
+// find collects the matches in lines, which are free of colors.
+func (s *viewerSearch) find(lines []string) {
+	s.matches = s.matches[:0]
+	if s.regex == nil {
+		return
+	}
+	for i, line := range lines {
+		// Where the line has a JSON key, it ends at keyEnd
+		keyEnd := 0
+		if loc := jsonKeyPattern.FindStringIndex(line); loc != nil {
+			keyEnd = loc[1]
+		}
+		for _, loc := range s.regex.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			inKey := loc[1] <= keyEnd
+			if (s.scope == searchKeys && !inKey) || (s.scope == searchValues && loc[0] < keyEnd) {
+				continue
+			}
+			s.matches = append(s.matches, searchMatch{line: i, start: loc[0], end: loc[1]})
+		}
+	}
+	s.current = max(min(s.current, len(s.matches)-1), 0)
+}
+
+// highlight replaces the lines that have matches with their plain text and
+// the matches marked. Other lines keep their colors.
+func (s *viewerSearch) highlight(colored, plain []string) string {
+	lines := append([]string(nil), colored...)
+	for i := 0; i < len(s.matches); {
+		line := s.matches[i].line
+		text := plain[line]
+		var sb strings.Builder
+		pos := 0
+		for ; i < len(s.matches) && s.matches[i].line == line; i++ {
+			match := s.matches[i]
+			style := searchMatchStyle
+			if i == s.current {
+				style = searchCurrentStyle
+			}
+			sb.WriteString(text[pos:match.start])
+			sb.WriteString(style.Render(text[match.start:match.end]))
+			pos = match.end
+		}
+		sb.WriteString(text[pos:])
+		lines[line] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// step moves to the next (1) or previous (-1) match, wrapping around.
+func (s *viewerSearch) step(direction int) {
+	if len(s.matches) > 0 {
+		s.current = (s.current + direction + len(s.matches)) % len(s.matches)
+	}
+}
+
+// status describes the search for the viewer footer.
+func (s *viewerSearch) status() string {
+	switch {
+	case s.err != nil:
+		return fmt.Sprintf("Invalid regex: %v", s.err)
+	case len(s.matches) == 0:
+		return fmt.Sprintf("No matches for %q", s.input.Value())
+	}
+	status := fmt.Sprintf("match %d/%d", s.current+1, len(s.matches))
+	if s.scope != searchAll {
+		status += fmt.Sprintf(" in %s", s.scope)
+	}
+	return status
+}
+
+// stripLines splits the wrapped viewer text into lines without colors.
+func stripLines(text string) []string {
+	return strings.Split(ansi.Strip(text), "\n")
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+)
+
+// QuerySettings are the query options applied to every query of a session.
+type QuerySettings struct {
+	BatchSize       int
+	MaxRuntime      float64
+	MemoryLimit     int64
+	FullCount       bool
+	Count           bool
+	OptimizerRules  []string
+	AllowDirtyReads bool
+	Cache           bool
+	FailOnWarning   bool
+}
+
+// querySetting describes a setting that can be changed with /set and in the
+// settings section of a database configuration.
+type querySetting struct {
+	name        string
+	description string
+	set         func(settings *QuerySettings, value string) error
+	get         func(settings QuerySettings) string
+}
+
+var querySettings = []querySetting{
+	{
+		name:        "batchSize",
+		description: "Documents fetched per cursor batch",
+		set: func(settings *QuerySettings, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("batchSize must be a positive integer")
+			}
+			settings.BatchSize = n
+			return nil
+		},
+		get: func(settings QuerySettings) string { return strconv.Itoa(settings.BatchSize) },
+	},
+	{
+		name:        "maxRuntime",
+		description: "Kill queries on the server after this many seconds (0 = no limit)",
+		set: func(settings *QuerySettings, value string) error {
+			seconds, err := strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64)
+			if err != nil || seconds < 0 {
+				return fmt.Errorf("maxRuntime must be a number of seconds")
+			}
+			settings.MaxRuntime = seconds
+			return nil
+		},
+		get: func(settings QuerySettings) string {
+			return strconv.FormatFloat(settings.MaxRuntime, 'f', -1, 64) + "s"
+		},
+	},
+	{
+		name:        "memoryLimit",
+		description: "Maximum memory a query may use, e.g. 512MB (0 = server default)",
+		set: func(settings *QuerySettings, value string) error {
+			limit, err := parseBytes(value)
+			if err != nil {
+				return err
+			}
+			settings.MemoryLimit = limit
+			return nil
+		},
+		get: func(settings QuerySettings) string {
+			if settings.MemoryLimit == 0 {
+				return "0"
+			}
+			return formatBytes(int(settings.MemoryLimit))
+		},
+	},
+	{
+		name:        "fullCount",
+		description: "Return the number of documents before the last LIMIT",
+		set: func(settings *QuerySettings, value string) error {
+			return parseBoolSetting(value, &settings.FullCount)
+		},
+		get: func(settings QuerySettings) string { return strconv.FormatBool(settings.FullCount) },
+	},
+	{
+		name:        "count",
+		description: "Ask the server for the total number of results",
+		set: func(settings *QuerySettings, value string) error {
+			return parseBoolSetting(value, &settings.Count)
+		},
+		get: func(settings QuerySettings) string { return strconv.FormatBool(settings.Count) },
+	},
+	{
+		name:        "optimizerRules",
+		description: "Comma separated optimizer rules, e.g. -all,+use-indexes (none to reset)",
+		set: func(settings *QuerySettings, value string) error {
+			settings.OptimizerRules = nil
+			if strings.EqualFold(value, "none") {
+				return nil
+			}
+			for _, rule := range strings.Split(value, ",") {
+				rule = strings.TrimSpace(rule)
+				if !strings.HasPrefix(rule, "+") && !strings.HasPrefix(rule, "-") {
+					return fmt.Errorf("optimizer rule '%s' must start with + or -", rule)
+				}
+				settings.OptimizerRules = append(settings.OptimizerRules, rule)
+			}
+			return nil
+		},
+		get: func(settings QuerySettings) string {
+			if len(settings.OptimizerRules) == 0 {
+				return "none"
+			}
+			return strings.Join(settings.OptimizerRules, ",")
+		},
+	},
+	{
+		name:        "allowDirtyReads",
+		description: "Allow reads from followers in an active failover or cluster setup",
+		set: func(settings *QuerySettings, value string) error {
+			return parseBoolSetting(value, &settings.AllowDirtyReads)
+		},
+		get: func(settings QuerySettings) string { return strconv.FormatBool(settings.AllowDirtyReads) },
+	},
+	{
+		name:        "cache",
+		description: "Use the AQL query results cache (server mode on or demand)",
+		set: func(settings *QuerySettings, value string) error {
+			return parseBoolSetting(value, &settings.Cache)
+		},
+		get: func(settings QuerySettings) string { return strconv.FormatBool(settings.Cache) },
+	},
+	{
+		name:        "failOnWarning",
+		description: "Treat queries that produce warnings as failed",
+		set: func(settings *QuerySettings, value string) error {
+			return parseBoolSetting(value, &settings.FailOnWarning)
+		},
+		get: func(settings QuerySettings) string { return strconv.FormatBool(settings.FailOnWarning) },
+	},
+}
+
+// defaultQuerySettings returns the settings of a session before any profile
+// defaults or /set commands are applied.
+func defaultQuerySettings() QuerySettings {
+	return QuerySettings{
+		BatchSize: defaultBatchSize,
+		Count:     true,
+	}
+}
+
+// newQuerySettings returns the default settings with the settings section of
+// a database configuration applied on top.
+func newQuerySettings(values map[string]string) (QuerySettings, error) {
+	settings := defaultQuerySettings()
+	for name, value := range values {
+		if err := settings.Set(name, value); err != nil {
+			return settings, err
+		}
+	}
+	return settings, nil
+}
+
+func findQuerySetting(name string) (*querySetting, error) {
+	for i := range querySettings {
+		if strings.EqualFold(querySettings[i].name, name) {
+			return &querySettings[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown setting '%s'", name)
+}
+
+// Set changes a setting by name, parsing value according to its type.
+func (q *QuerySettings) Set(name, value string) error {
+	setting, err := findQuerySetting(name)
+	if err != nil {
+		return err
+	}
+	return setting.set(q, strings.TrimSpace(value))
+}
+
+// apply adds the query options of the settings to ctx.
+func (q QuerySettings) apply(ctx context.Context) context.Context {
+	ctx = driver.WithQueryCount(ctx, q.Count)
+	if q.BatchSize > 0 {
+		ctx = driver.WithQueryBatchSize(ctx, q.BatchSize)
+	}
+	if q.MaxRuntime > 0 {
+		ctx = driver.WithQueryMaxRuntime(ctx, q.MaxRuntime)
+	}
+	if q.MemoryLimit > 0 {
+		ctx = driver.WithQueryMemoryLimit(ctx, q.MemoryLimit)
+	}
+	if q.FullCount {
+		ctx = driver.WithQueryFullCount(ctx, true)
+	}
+	if len(q.OptimizerRules) > 0 {
+		ctx = driver.WithQueryOptimizerRules(ctx, q.OptimizerRules)
+	}
+	if q.AllowDirtyReads {
+		ctx = driver.WithAllowDirtyReads(ctx, new(bool))
+	}
+	if q.Cache {
+		ctx = driver.WithQueryCache(ctx, true)
+	}
+	return ctx
+}
+
+func (s *ShellContext) setSetting(name, value string) {
+	if name == "" || value == "" {
+		fmt.Println("Usage: /set <setting> <value>  (see /show settings)")
+		return
+	}
+	if err := s.Settings.Set(name, value); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	setting, _ := findQuerySetting(name)
+	fmt.Printf("%s = %s\n", setting.name, setting.get(s.Settings))
+}
+
+func (s *ShellContext) resetSetting(name string) {
+	setting, err := findQuerySetting(name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defaults := defaultQuerySettings()
+	setting.set(&s.Settings, setting.get(defaults))
+	fmt.Printf("%s reset to %s\n", setting.name, setting.get(s.Settings))
+}
+
+func (s *ShellContext) showSettings() {
+	fmt.Println("Query settings:")
+	for _, setting := range querySettings {
+		fmt.Printf("  %-16s %-12s %s\n", setting.name, setting.get(s.Settings), setting.description)
+	}
+}
+
+func parseBoolSetting(value string, target *bool) error {
+	switch strings.ToLower(value) {
+	case "true", "on", "yes", "1":
+		*target = true
+	case "false", "off", "no", "0":
+		*target = false
+	default:
+		return fmt.Errorf("expected true or false, got '%s'", value)
+	}
+	return nil
+}
+
+// parseBytes parses a size such as 1048576, 512KB, 64MB or 2GB.
+func parseBytes(value string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for i, unit := range []string{"KB", "MB", "GB", "TB"} {
+		if strings.HasSuffix(upper, unit) {
+			multiplier = int64(1) << (10 * (i + 1))
+			upper = strings.TrimSpace(strings.TrimSuffix(upper, unit))
+			break
+		}
+	}
+	upper = strings.TrimSuffix(upper, "B")
+
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s', expected e.g. 1048576, 512KB or 64MB", value)
+	}
+	return n * multiplier, nil
+}
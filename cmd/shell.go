@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	driver "github.com/arangodb/go-driver"
 	"github.com/arangodb/go-driver/http"
@@ -21,6 +22,16 @@ type (
 		ConnectionURL string
 		ConfigManager *ConfigManager
 		CurrentConfig string
+		Format        string
+		BindVars      map[string]interface{}
+		Settings      QuerySettings
+		// Transaction is the stream transaction queries run in, if any
+		Transaction      driver.TransactionID
+		TransactionStart time.Time
+		// History holds the statements executed in the interactive shell
+		History *queryHistory
+		// Completion caches the schema information used for completion
+		Completion *completionCache
 	}
 	ShellConfig struct {
 		Host     string
@@ -72,6 +83,10 @@ func NewShellContext(config *ShellConfig) (*ShellContext, error) {
 		Context:       ctx,
 		Config:        config,
 		ConnectionURL: connectionURL,
+		Format:        defaultShellFormat,
+		BindVars:      map[string]interface{}{},
+		Settings:      defaultQuerySettings(),
+		Completion:    newCompletionCache(),
 	}, nil
 }
 
@@ -83,6 +98,14 @@ func NewShellContextWithConfig(config *ShellConfig, configManager *ConfigManager
 
 	shellCtx.ConfigManager = configManager
 	shellCtx.CurrentConfig = configName
+
+	if dbConfig, err := configManager.GetDatabaseConfig(configName); err == nil {
+		settings, err := newQuerySettings(dbConfig.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings in config '%s': %v", configName, err)
+		}
+		shellCtx.Settings = settings
+	}
 	return shellCtx, nil
 }
 
@@ -99,13 +122,40 @@ func (s *ShellContext) showCurrentConnection() {
 
 func startShell(s *ShellContext) {
 	promptPrefix := func() string {
+		location := s.CurrentDB
 		if s.CurrentConfig != "manual" {
-			return fmt.Sprintf("arango[%s:%s]> ", s.CurrentConfig, s.CurrentDB)
+			location = fmt.Sprintf("%s:%s", s.CurrentConfig, s.CurrentDB)
+		}
+		if s.Transaction != "" {
+			location += fmt.Sprintf(" trx:%s", s.Transaction)
 		}
-		return fmt.Sprintf("arango[%s]> ", s.CurrentDB)
+		return fmt.Sprintf("arango[%s]> ", location)
+	}
+	s.History = loadHistory(s.ConfigManager, s.CurrentConfig)
+	options := []prompt.Option{
+		prompt.OptionPrefix(promptPrefix()),
+		prompt.OptionLivePrefix(func() (string, bool) {
+			if prefix, ok := s.History.searchPrefix(); ok {
+				return prefix, true
+			}
+			return promptPrefix(), true
+		}),
+		prompt.OptionTitle("ArangoDB Shell"),
+		prompt.OptionCompletionWordSeparator(completionWordSeparator),
+		prompt.OptionHistory(s.History.statements()),
+		prompt.OptionAddKeyBind(s.History.keyBindings()...),
+	}
+	if t := currentTheme(); t != (theme{}) {
+		options = append(options, prompt.OptionWriter(newHighlightWriter(t)), prompt.OptionInputTextColor(inputColor))
 	}
 	p := prompt.New(
 		func(input string) {
+			// Enter during a Ctrl-R search runs the matching statement
+			if query, ok := s.History.acceptSearch(); ok {
+				s.executeQuery(query)
+				return
+			}
+
 			input = strings.TrimSpace(input)
 			if input == "" {
 				return
@@ -113,6 +163,7 @@ func startShell(s *ShellContext) {
 
 			switch strings.ToLower(input) {
 			case "exit", "quit":
+				s.abortOpenTransaction("exit")
 				fmt.Println("Goodbye!")
 				os.Exit(0)
 			case "help":
@@ -127,9 +178,9 @@ func startShell(s *ShellContext) {
 
 			s.executor(input)
 		},
-		completer,
-		prompt.OptionPrefix(promptPrefix()),
-		prompt.OptionTitle("ArangoDB Shell"),
+		s.completer,
+		options...,
 	)
 	p.Run()
+	s.abortOpenTransaction("exit")
 }
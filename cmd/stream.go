@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	driver "github.com/arangodb/go-driver"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultBatchSize = 1000
+
+// streamRenderer renders the documents of a result stream. Each batch is
+// rendered once and appended to the text of the batches before it.
+type streamRenderer struct {
+	// batch renders documents that were just loaded
+	batch func(docs []interface{}, first bool) string
+	// finish renders the end of the content once all documents are loaded
+	finish func(docs []interface{}) string
+	// whole is set for formats that need all documents at once. They are
+	// rendered by finish, and all batches are loaded without waiting for
+	// the user to scroll.
+	whole bool
+}
+
+// resultStream feeds the documents of a cursor into the viewer one batch at a
+// time. It is only mutated from the bubbletea update loop.
+type resultStream struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cursor    driver.Cursor
+	batchSize int
+	// total is the number of documents the cursor will return, or 0 if unknown
+	total  int64
+	render streamRenderer
+
+	docs    []interface{}
+	started bool
+	done    bool
+	loading bool
+	err     error
+
+	// mu is held while a batch is read, so close waits for a running fetch
+	mu     sync.Mutex
+	closed bool
+}
+
+// batchMsg carries the documents read by fetchBatch back into the update loop.
+type batchMsg struct {
+	docs []interface{}
+	done bool
+	err  error
+}
+
+func newResultStream(ctx context.Context, cursor driver.Cursor, batchSize int, render streamRenderer) *resultStream {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &resultStream{
+		ctx:       ctx,
+		cancel:    cancel,
+		cursor:    cursor,
+		batchSize: batchSize,
+		total:     cursor.Count(),
+		render:    render,
+	}
+}
+
+// fetchBatch returns a command that reads the next batch from the cursor.
+func (r *resultStream) fetchBatch() tea.Cmd {
+	if r.done || r.loading {
+		return nil
+	}
+	r.loading = true
+
+	cursor, ctx, batchSize := r.cursor, r.ctx, r.batchSize
+	return func() tea.Msg {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.closed {
+			return batchMsg{done: true, err: context.Canceled}
+		}
+		docs := make([]interface{}, 0, batchSize)
+		for len(docs) < batchSize {
+			var doc interface{}
+			_, err := cursor.ReadDocument(ctx, &doc)
+			if driver.IsNoMoreDocuments(err) {
+				return batchMsg{docs: docs, done: true}
+			} else if err != nil {
+				return batchMsg{docs: docs, done: true, err: err}
+			}
+			docs = append(docs, doc)
+		}
+		return batchMsg{docs: docs, done: !cursor.HasMore()}
+	}
+}
+
+// apply stores a fetched batch and returns the text to append to the viewer
+// content.
+func (r *resultStream) apply(msg batchMsg) string {
+	first := !r.started
+	r.started = true
+	r.docs = append(r.docs, msg.docs...)
+	r.done = msg.done
+	r.loading = false
+	r.err = msg.err
+
+	var text string
+	if !r.render.whole {
+		text = r.render.batch(msg.docs, first)
+	}
+	if r.done {
+		text += r.render.finish(r.docs)
+	}
+	if r.err != nil {
+		text += fmt.Sprintf("\nError reading result: %v\n", r.err)
+	}
+	return text
+}
+
+// eager reports whether the next batch should be loaded right away, because
+// nothing is shown before all documents are loaded.
+func (r *resultStream) eager() bool {
+	return r.render.whole && !r.done
+}
+
+// close cancels a running fetch, waits for it to return and closes the
+// cursor.
+func (r *resultStream) close() error {
+	r.cancel()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.cursor.Close()
+}
+
+// progress describes how many documents are loaded, for the viewer header.
+func (r *resultStream) progress() string {
+	status := fmt.Sprintf("%d", len(r.docs))
+	if r.total > 0 {
+		status = fmt.Sprintf("%d of %d", len(r.docs), r.total)
+	}
+	if r.loading {
+		status += ", loading..."
+	} else if !r.done {
+		status += ", scroll down for more"
+	}
+	return status
+}
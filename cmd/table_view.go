@@ -0,0 +1,579 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxColumnWidth caps a column so one long attribute doesn't push all
+	// others off screen
+	maxColumnWidth = 40
+	minColumnWidth = 3
+)
+
+type tableColumn struct {
+	name   string
+	width  int
+	hidden bool
+}
+
+// tableView shows documents as rows of a table, one column per (flattened)
+// attribute. Columns that don't fit the width are scrolled horizontally.
+type tableView struct {
+	records []map[string]interface{}
+	columns []tableColumn
+	// selected indexes columns, offset is the first column on screen
+	selected int
+	offset   int
+
+	sortColumn string
+	sortDesc   bool
+
+	filter     string
+	conditions []filterCondition
+	input      textinput.Model
+	editing    bool
+	message    string
+
+	table table.Model
+	// shown are the records behind the rows of table
+	shown  []map[string]interface{}
+	width  int
+	height int
+}
+
+func newTableView() *tableView {
+	keys := table.DefaultKeyMap()
+	// Leave the letters to the column commands
+	keys.LineUp = key.NewBinding(key.WithKeys("up", "k"))
+	keys.LineDown = key.NewBinding(key.WithKeys("down", "j"))
+	keys.PageUp = key.NewBinding(key.WithKeys("pgup"))
+	keys.PageDown = key.NewBinding(key.WithKeys("pgdown", " "))
+	keys.HalfPageUp = key.NewBinding(key.WithKeys("ctrl+u"))
+	keys.HalfPageDown = key.NewBinding(key.WithKeys("ctrl+d"))
+	keys.GotoTop = key.NewBinding(key.WithKeys("home", "g"))
+	keys.GotoBottom = key.NewBinding(key.WithKeys("end", "G"))
+
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	styles.Selected = styles.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57"))
+
+	input := textinput.New()
+	input.Prompt = "Filter: "
+	input.Placeholder = "name == \"Alice\" && age >= 30"
+
+	return &tableView{
+		table: table.New(table.WithKeyMap(keys), table.WithStyles(styles), table.WithFocused(true)),
+		input: input,
+	}
+}
+
+// setDocuments adds the documents not seen yet. Columns of new attributes are
+// appended so the order chosen by the user is kept.
+func (v *tableView) setDocuments(docs []interface{}) {
+	if len(docs) == len(v.records) {
+		return
+	}
+	seen := map[string]bool{}
+	for _, column := range v.columns {
+		seen[column.name] = true
+	}
+	var added []string
+	for _, item := range docs[len(v.records):] {
+		record, ok := item.(map[string]interface{})
+		if ok {
+			record = flattenDocument(record)
+		} else {
+			record = map[string]interface{}{"value": item}
+		}
+		v.records = append(v.records, record)
+
+		for name := range record {
+			if !seen[name] {
+				seen[name] = true
+				added = append(added, name)
+			}
+		}
+	}
+	sortColumns(added)
+	for _, name := range added {
+		v.columns = append(v.columns, tableColumn{name: name})
+	}
+	v.measure()
+	v.refresh()
+}
+
+// measure sizes every column to its widest cell.
+func (v *tableView) measure() {
+	for i := range v.columns {
+		column := &v.columns[i]
+		width := lipgloss.Width(column.name) + 4 // room for the sort and selection markers
+		for _, record := range v.records {
+			if value, ok := record[column.name]; ok {
+				width = max(width, lipgloss.Width(cellText(value)))
+			}
+			if width >= maxColumnWidth {
+				break
+			}
+		}
+		column.width = max(min(width, maxColumnWidth), minColumnWidth)
+	}
+}
+
+// cellText renders a value on a single line.
+func cellText(value interface{}) string {
+	return strings.NewReplacer("\n", "⏎", "\r", "", "\t", " ").Replace(formatCell(value))
+}
+
+func (v *tableView) setSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.input.Width = width - lipgloss.Width(v.input.Prompt) - 1
+	// One line below the table for the filter and sort status
+	v.table.SetHeight(height - 1)
+	v.refresh()
+}
+
+// refresh rebuilds the rows after the documents, columns, sort order or
+// filter changed, keeping the cursor on the same row number.
+func (v *tableView) refresh() {
+	if v.width == 0 {
+		return
+	}
+	v.scrollToSelected()
+
+	var records []map[string]interface{}
+	for _, record := range v.records {
+		if matchesConditions(record, v.conditions) {
+			records = append(records, record)
+		}
+	}
+	if v.sortColumn != "" {
+		sort.SliceStable(records, func(i, j int) bool {
+			a, aok := records[i][v.sortColumn]
+			b, bok := records[j][v.sortColumn]
+			// Documents without the attribute go last in either direction
+			if !aok || !bok {
+				return aok && !bok
+			}
+			if v.sortDesc {
+				return compareValues(b, a) < 0
+			}
+			return compareValues(a, b) < 0
+		})
+	}
+
+	var (
+		columns []table.Column
+		names   []string
+		used    int
+	)
+	for i := v.offset; i < len(v.columns); i++ {
+		column := v.columns[i]
+		if column.hidden {
+			continue
+		}
+		// Cells are padded by one space on each side
+		if used > 0 && used+column.width+2 > v.width {
+			break
+		}
+		title := column.name
+		if column.name == v.sortColumn {
+			if v.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		if i == v.selected {
+			title = "[" + title + "]"
+		}
+		columns = append(columns, table.Column{Title: title, Width: min(column.width, v.width-2)})
+		names = append(names, column.name)
+		used += column.width + 2
+	}
+
+	v.shown = records
+	rows := make([]table.Row, len(records))
+	for i, record := range records {
+		row := make(table.Row, len(names))
+		for j, name := range names {
+			if value, ok := record[name]; ok {
+				row[j] = cellText(value)
+			}
+		}
+		rows[i] = row
+	}
+
+	cursor := v.table.Cursor()
+	// Shrink the rows before the columns so they never disagree in length
+	v.table.SetRows(nil)
+	v.table.SetColumns(columns)
+	v.table.SetRows(rows)
+	v.table.SetCursor(max(cursor, 0))
+}
+
+// visibleColumns returns the indexes of the columns that aren't hidden.
+func (v *tableView) visibleColumns() []int {
+	var visible []int
+	for i, column := range v.columns {
+		if !column.hidden {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// scrollToSelected moves the selection off hidden columns and adjusts the
+// horizontal offset so the selected column is on screen.
+func (v *tableView) scrollToSelected() {
+	visible := v.visibleColumns()
+	if len(visible) == 0 {
+		v.selected, v.offset = 0, 0
+		return
+	}
+	if v.selected >= len(v.columns) || v.columns[v.selected].hidden {
+		next := visible[len(visible)-1]
+		for _, i := range visible {
+			if i >= v.selected {
+				next = i
+				break
+			}
+		}
+		v.selected = next
+	}
+
+	if v.offset > v.selected {
+		v.offset = v.selected
+	}
+	for v.offset < v.selected {
+		used := 0
+		for i := v.offset; i <= v.selected; i++ {
+			if !v.columns[i].hidden {
+				used += v.columns[i].width + 2
+			}
+		}
+		if used <= v.width {
+			break
+		}
+		v.offset++
+	}
+}
+
+// moveSelection selects the next visible column in direction step.
+func (v *tableView) moveSelection(step int) {
+	for i := v.selected + step; i >= 0 && i < len(v.columns); i += step {
+		if !v.columns[i].hidden {
+			v.selected = i
+			return
+		}
+	}
+}
+
+// moveColumn swaps the selected column with its visible neighbour.
+func (v *tableView) moveColumn(step int) {
+	for i := v.selected + step; i >= 0 && i < len(v.columns); i += step {
+		if !v.columns[i].hidden {
+			v.columns[v.selected], v.columns[i] = v.columns[i], v.columns[v.selected]
+			v.selected = i
+			return
+		}
+	}
+}
+
+// cycleSort sorts by the selected column, ascending, then descending, then
+// not at all.
+func (v *tableView) cycleSort() {
+	name := v.columns[v.selected].name
+	switch {
+	case v.sortColumn != name:
+		v.sortColumn, v.sortDesc = name, false
+	case !v.sortDesc:
+		v.sortDesc = true
+	default:
+		v.sortColumn, v.sortDesc = "", false
+	}
+}
+
+// update handles a key in table mode.
+func (v *tableView) update(msg tea.KeyMsg) tea.Cmd {
+	if v.editing {
+		switch msg.String() {
+		case "enter":
+			conditions, err := parseFilter(v.input.Value())
+			if err != nil {
+				v.message = err.Error()
+				return nil
+			}
+			v.filter, v.conditions = strings.TrimSpace(v.input.Value()), conditions
+			v.editing, v.message = false, ""
+			v.input.Blur()
+			v.table.SetCursor(0)
+			v.refresh()
+		case "esc":
+			v.editing, v.message = false, ""
+			v.input.Blur()
+		default:
+			var cmd tea.Cmd
+			v.input, cmd = v.input.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	v.message = ""
+	switch msg.String() {
+	case "left", "h":
+		v.moveSelection(-1)
+	case "right", "l":
+		v.moveSelection(1)
+	case "<":
+		v.moveColumn(-1)
+	case ">":
+		v.moveColumn(1)
+	case "x":
+		if len(v.visibleColumns()) > 1 {
+			v.columns[v.selected].hidden = true
+		} else {
+			v.message = "Can't hide the last column"
+		}
+	case "a":
+		for i := range v.columns {
+			v.columns[i].hidden = false
+		}
+	case "s":
+		if len(v.columns) > 0 {
+			v.cycleSort()
+		}
+	case "f":
+		v.editing = true
+		v.input.SetValue(v.filter)
+		v.input.CursorEnd()
+		return v.input.Focus()
+	default:
+		var cmd tea.Cmd
+		v.table, cmd = v.table.Update(msg)
+		return cmd
+	}
+	v.refresh()
+	return nil
+}
+
+// selection returns the value of the selected column in the row under the
+// cursor and the document of that row.
+func (v *tableView) selection() (interface{}, interface{}) {
+	cursor := v.table.Cursor()
+	if cursor < 0 || cursor >= len(v.shown) || len(v.columns) == 0 {
+		return nil, nil
+	}
+	record := v.shown[cursor]
+	return record[v.columns[v.selected].name], record
+}
+
+// nearEnd reports whether the cursor is within a page of the last row, so
+// the viewer should load more documents.
+func (v *tableView) nearEnd() bool {
+	return len(v.table.Rows())-v.table.Cursor() < v.table.Height()
+}
+
+func (v *tableView) view() string {
+	var status string
+	switch {
+	case v.editing:
+		status = v.input.View()
+	case v.message != "":
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(v.message)
+	default:
+		status = fmt.Sprintf("Row %d/%d", v.table.Cursor()+1, len(v.table.Rows()))
+		if len(v.table.Rows()) == 0 {
+			status = "No rows"
+		}
+		if v.filter != "" {
+			status += fmt.Sprintf(" of %d • Filter: %s", len(v.records), v.filter)
+		}
+		if hidden := len(v.columns) - len(v.visibleColumns()); hidden > 0 {
+			status += fmt.Sprintf(" • %d hidden", hidden)
+		}
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(status)
+	}
+	return lipgloss.NewStyle().Width(v.width).Render(v.table.View() + "\n" + status)
+}
+
+// filterCondition is one comparison of a filter expression. An empty column
+// matches the value against every cell.
+type filterCondition struct {
+	column string
+	op     string
+	value  string
+	regex  *regexp.Regexp
+}
+
+var filterPattern = regexp.MustCompile(`^\s*([^\s=!<>~]+)\s*(==|!=|>=|<=|=~|=|>|<|~)\s*(.*?)\s*$`)
+
+// parseFilter parses a filter expression: comparisons like `age >= 30` or
+// `name ~ ali` joined with `&&`. The operators are ==, !=, <, <=, >, >=, ~
+// (contains, ignoring case) and =~ (regular expression). A term without an
+// operator matches documents that contain it in any column.
+func parseFilter(expr string) ([]filterCondition, error) {
+	var conditions []filterCondition
+	for _, term := range strings.Split(expr, "&&") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		match := filterPattern.FindStringSubmatch(term)
+		if match == nil {
+			conditions = append(conditions, filterCondition{op: "~", value: unquote(term)})
+			continue
+		}
+		condition := filterCondition{column: match[1], op: match[2], value: unquote(match[3])}
+		if condition.op == "=" {
+			condition.op = "=="
+		}
+		if condition.op == "=~" {
+			regex, err := regexp.Compile(condition.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %v", condition.value, err)
+			}
+			condition.regex = regex
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// unquote strips matching quotes around a filter value.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func matchesConditions(record map[string]interface{}, conditions []filterCondition) bool {
+	for _, condition := range conditions {
+		if condition.column == "" {
+			if !matchesAnyCell(record, condition.value) {
+				return false
+			}
+			continue
+		}
+		value, ok := record[condition.column]
+		if !ok {
+			if condition.op != "!=" {
+				return false
+			}
+			continue
+		}
+		if !condition.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyCell(record map[string]interface{}, text string) bool {
+	text = strings.ToLower(text)
+	for _, value := range record {
+		if strings.Contains(strings.ToLower(formatCell(value)), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c filterCondition) matches(value interface{}) bool {
+	text := formatCell(value)
+	switch c.op {
+	case "~":
+		return strings.Contains(strings.ToLower(text), strings.ToLower(c.value))
+	case "=~":
+		return c.regex.MatchString(text)
+	}
+
+	// Compare numbers as numbers when both sides are numeric
+	var result int
+	if number, ok := value.(float64); ok {
+		if other, err := strconv.ParseFloat(c.value, 64); err == nil {
+			result = compareValues(number, other)
+		} else {
+			result = strings.Compare(text, c.value)
+		}
+	} else {
+		result = strings.Compare(text, c.value)
+	}
+
+	switch c.op {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// compareValues orders values like AQL does: null, booleans, numbers,
+// strings, then arrays and objects by their JSON text.
+func compareValues(a, b interface{}) int {
+	rank := func(value interface{}) int {
+		switch value.(type) {
+		case nil:
+			return 0
+		case bool:
+			return 1
+		case float64:
+			return 2
+		case string:
+			return 3
+		}
+		return 4
+	}
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+	switch a := a.(type) {
+	case bool:
+		if a == b.(bool) {
+			return 0
+		} else if !a {
+			return -1
+		}
+		return 1
+	case float64:
+		if a < b.(float64) {
+			return -1
+		} else if a > b.(float64) {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case nil:
+		return 0
+	}
+	return strings.Compare(formatCell(a), formatCell(b))
+}
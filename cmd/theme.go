@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// theme holds the colors used for AQL input and JSON results. The zero theme
+// leaves all text as it is.
+type theme struct {
+	Keyword   color
+	Function  color
+	String    color
+	Number    color
+	BindParam color
+	Comment   color
+	Key       color
+	Boolean   color
+	Null      color
+}
+
+// color is a style resolved to the escape codes around a text, so coloring
+// the many tokens of a large result stays cheap.
+type color struct {
+	start string
+	end   string
+}
+
+func newColor(style lipgloss.Style) color {
+	rendered := style.Render("x")
+	i := strings.Index(rendered, "x")
+	return color{start: rendered[:i], end: rendered[i+1:]}
+}
+
+func (c color) paint(text string) string {
+	if c.start == "" {
+		return text
+	}
+	return c.start + text + c.end
+}
+
+var (
+	themeOnce sync.Once
+	theTheme  theme
+)
+
+// currentTheme returns the theme to render with. Colors are only used when
+// stdout is a terminal and NO_COLOR (https://no-color.org) is not set.
+func currentTheme() theme {
+	themeOnce.Do(func() {
+		if os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+			return
+		}
+		theTheme = theme{
+			Keyword:   newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)),
+			Function:  newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("75"))),
+			String:    newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("114"))),
+			Number:    newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("215"))),
+			BindParam: newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("80"))),
+			Comment:   newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Italic(true)),
+			Key:       newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("81"))),
+			Boolean:   newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("170"))),
+			Null:      newColor(lipgloss.NewStyle().Foreground(lipgloss.Color("243"))),
+		}
+	})
+	return theTheme
+}
+
+// colorizeJSON colors the keys and values of the JSON text data, typically
+// the output of json.MarshalIndent.
+func colorizeJSON(data string, t theme) string {
+	if t == (theme{}) {
+		return data
+	}
+	var sb strings.Builder
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(data) && data[end] != '"' {
+				if data[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(data))
+			paint := t.String
+			// A string followed by a colon is an object key
+			if strings.HasPrefix(strings.TrimLeft(data[end:], " \t\n"), ":") {
+				paint = t.Key
+			}
+			sb.WriteString(paint.paint(data[i:end]))
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(data) && strings.IndexByte("0123456789.eE+-", data[end]) >= 0 {
+				end++
+			}
+			sb.WriteString(t.Number.paint(data[i:end]))
+			i = end
+		case strings.HasPrefix(data[i:], "true"):
+			sb.WriteString(t.Boolean.paint("true"))
+			i += len("true")
+		case strings.HasPrefix(data[i:], "false"):
+			sb.WriteString(t.Boolean.paint("false"))
+			i += len("false")
+		case strings.HasPrefix(data[i:], "null"):
+			sb.WriteString(t.Null.paint("null"))
+			i += len("null")
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/pflag"
+)
+
+// beginTransaction starts a stream transaction. All following queries of the
+// session run inside it until /commit or /abort.
+func (s *ShellContext) beginTransaction(args []string) {
+	if s.Transaction != "" {
+		fmt.Printf("Transaction %s is already running, /commit or /abort it first\n", s.Transaction)
+		return
+	}
+
+	var (
+		cols    driver.TransactionCollections
+		options driver.BeginTransactionOptions
+	)
+	fs := newShellFlagSet("/begin")
+	fs.StringSliceVar(&cols.Read, "read", nil, "Collections read by the transaction")
+	fs.StringSliceVar(&cols.Write, "write", nil, "Collections written by the transaction")
+	fs.StringSliceVar(&cols.Exclusive, "exclusive", nil, "Collections locked exclusively")
+	fs.DurationVar(&options.LockTimeout, "lock-timeout", 0, "How long to wait for collection locks")
+	fs.BoolVar(&options.WaitForSync, "wait-for-sync", false, "Wait until the commit is synced to disk")
+	fs.BoolVar(&options.AllowImplicit, "allow-implicit", true, "Allow reading from undeclared collections")
+	if err := fs.Parse(args); err != nil {
+		if err != pflag.ErrHelp {
+			fmt.Printf("Error: %v\n", err)
+		}
+		return
+	}
+	if len(cols.Read)+len(cols.Write)+len(cols.Exclusive) == 0 {
+		fmt.Println("Usage: /begin [--read a,b] [--write c] [--exclusive d] [--lock-timeout 5s] [--wait-for-sync]")
+		return
+	}
+
+	tid, err := s.DB.BeginTransaction(s.Context, cols, &options)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	s.Transaction = tid
+	s.TransactionStart = time.Now()
+	fmt.Printf("Transaction %s started\n", tid)
+}
+
+func (s *ShellContext) commitTransaction() {
+	if s.Transaction == "" {
+		fmt.Println("No transaction is running")
+		return
+	}
+	if err := s.DB.CommitTransaction(s.Context, s.Transaction, nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		s.checkTransactionStatus()
+		return
+	}
+	fmt.Printf("Transaction %s committed after %v\n", s.Transaction, s.transactionAge())
+	s.Transaction = ""
+}
+
+func (s *ShellContext) abortTransaction() {
+	if s.Transaction == "" {
+		fmt.Println("No transaction is running")
+		return
+	}
+	if err := s.DB.AbortTransaction(s.Context, s.Transaction, nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		s.checkTransactionStatus()
+		return
+	}
+	fmt.Printf("Transaction %s aborted\n", s.Transaction)
+	s.Transaction = ""
+}
+
+// abortOpenTransaction aborts a running transaction before the session leaves
+// its database, warning the user that the changes were discarded.
+func (s *ShellContext) abortOpenTransaction(reason string) {
+	if s.Transaction == "" {
+		return
+	}
+	fmt.Printf("Warning: aborting transaction %s on %s, its changes are discarded\n", s.Transaction, reason)
+	if err := s.DB.AbortTransaction(s.Context, s.Transaction, nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	s.Transaction = ""
+}
+
+// checkTransactionStatus forgets the transaction if the server no longer
+// considers it running, e.g. after it timed out.
+func (s *ShellContext) checkTransactionStatus() {
+	status, err := s.DB.TransactionStatus(s.Context, s.Transaction)
+	if err == nil && status.Status == driver.TransactionRunning {
+		return
+	}
+	fmt.Printf("Transaction %s is no longer running\n", s.Transaction)
+	s.Transaction = ""
+}
+
+func (s *ShellContext) transactionAge() time.Duration {
+	return time.Since(s.TransactionStart).Round(time.Millisecond)
+}
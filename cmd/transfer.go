@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/spf13/cobra"
+)
+
+// transferCheckpoint is persisted after every committed batch, so that an
+// interrupted transfer can continue with --resume.
+type transferCheckpoint struct {
+	Direction  string    `json:"direction"`
+	Collection string    `json:"collection"`
+	File       string    `json:"file"`
+	LastKey    string    `json:"lastKey"`
+	Offset     int64     `json:"offset"`
+	Line       int       `json:"line"`
+	Processed  int64     `json:"processed"`
+	Skipped    int64     `json:"skipped"`
+	Failed     int64     `json:"failed"`
+	Updated    time.Time `json:"updated"`
+}
+
+// transferOptions holds the flags shared by the transfer subcommands.
+type transferOptions struct {
+	collection string
+	file       string
+	checkpoint string
+	resume     bool
+	batchSize  int
+	retries    int
+}
+
+// newTransferCmd builds the transfer commands. The same tree backs
+// `arango-cli transfer` and the shell's /transfer command.
+func newTransferCmd(use string, session sessionFunc) *cobra.Command {
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Copy a collection to or from a JSONL file with resumable checkpoints",
+		Long: `Copy a collection to or from a JSONL file in batches ordered by _key.
+After every batch a checkpoint file (<file>.checkpoint unless --checkpoint is
+given) records how far the transfer got. If the transfer fails or is cancelled,
+run the same command with --resume to continue after the last committed batch.
+Batches are written idempotently, so repeating a batch does no harm.`,
+	}
+	c.AddCommand(
+		newTransferDirectionCmd("export", "Copy a collection into a JSONL file", session),
+		newTransferDirectionCmd("import", "Copy a JSONL file into a collection", session),
+	)
+	return c
+}
+
+func newTransferDirectionCmd(direction, short string, session sessionFunc) *cobra.Command {
+	var o transferOptions
+	c := &cobra.Command{
+		Use:   direction,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := session()
+			if err != nil {
+				return err
+			}
+			return s.transfer(direction, o)
+		},
+	}
+	c.Flags().StringVar(&o.collection, "collection", "", "Collection to copy")
+	c.Flags().StringVarP(&o.file, "file", "f", "", "JSONL file to copy")
+	c.Flags().StringVar(&o.checkpoint, "checkpoint", "", "Checkpoint file (default: <file>.checkpoint)")
+	c.Flags().BoolVar(&o.resume, "resume", false, "Continue after the last committed batch of the checkpoint")
+	c.Flags().IntVar(&o.batchSize, "batch-size", defaultBatchSize, "Documents per batch")
+	c.Flags().IntVar(&o.retries, "retries", 5, "Attempts per batch before giving up")
+	c.MarkFlagRequired("collection")
+	c.MarkFlagRequired("file")
+	return c
+}
+
+func (s *ShellContext) transfer(direction string, o transferOptions) error {
+	if o.batchSize <= 0 || o.retries <= 0 {
+		return fmt.Errorf("--batch-size and --retries must be positive")
+	}
+	if o.checkpoint == "" {
+		o.checkpoint = o.file + ".checkpoint"
+	}
+
+	checkpoint := transferCheckpoint{Direction: direction, Collection: o.collection, File: o.file}
+	if o.resume {
+		if err := readJSONFile(o.checkpoint, &checkpoint); err != nil {
+			return fmt.Errorf("cannot resume: %v", err)
+		}
+		if checkpoint.Direction != direction || checkpoint.Collection != o.collection {
+			return fmt.Errorf("checkpoint %s belongs to a %s of collection '%s'", o.checkpoint, checkpoint.Direction, checkpoint.Collection)
+		}
+		fmt.Printf("Resuming after %d documents (last key '%s')\n", checkpoint.Processed, checkpoint.LastKey)
+	} else if _, err := os.Stat(o.checkpoint); err == nil {
+		return fmt.Errorf("checkpoint %s exists, use --resume to continue the previous transfer or delete it", o.checkpoint)
+	}
+
+	col, err := s.DB.Collection(s.Context, o.collection)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(s.Context)
+	defer cancel()
+	t := &transferRun{s: s, col: col, o: o, checkpoint: checkpoint}
+
+	var run func() error
+	var total func() int64
+	if direction == "export" {
+		count, err := col.Count(s.Context)
+		if err != nil {
+			return err
+		}
+		run = func() error { return t.export(ctx) }
+		total = func() int64 { return count }
+	} else {
+		info, err := os.Stat(o.file)
+		if err != nil {
+			return err
+		}
+		run = func() error { return t.importFile(ctx) }
+		total = func() int64 { return info.Size() }
+	}
+
+	if isTerminal(os.Stdout) {
+		title := fmt.Sprintf("Transferring %s to %s", o.collection, filepath.Base(o.file))
+		if direction == "import" {
+			title = fmt.Sprintf("Transferring %s to %s", filepath.Base(o.file), o.collection)
+		}
+		err = runWithProgress(title, cancel, run, func() progressState {
+			c := t.snapshot()
+			done := c.Processed
+			if direction == "import" {
+				done = c.Offset
+			}
+			return progressState{
+				done:   done,
+				total:  total(),
+				status: fmt.Sprintf("%d processed, %d skipped, %d failed, last key '%s'", c.Processed, c.Skipped, c.Failed, c.LastKey),
+			}
+		})
+	} else {
+		err = run()
+	}
+
+	for _, problem := range t.problems {
+		if problem.line > 0 {
+			fmt.Printf("line %d: %s\n", problem.line, problem.message)
+		} else {
+			fmt.Println(problem.message)
+		}
+	}
+	c := t.snapshot()
+	fmt.Printf("Processed %d, skipped %d, failed %d documents\n", c.Processed, c.Skipped, c.Failed)
+	if ctx.Err() != nil {
+		err = fmt.Errorf("transfer cancelled")
+	}
+	if err != nil {
+		return fmt.Errorf("%v; run the same command with --resume to continue after key '%s'", err, c.LastKey)
+	}
+
+	// The transfer is complete, so the checkpoint is no longer needed
+	os.Remove(o.checkpoint)
+	if c.Failed > 0 {
+		return fmt.Errorf("%d documents failed", c.Failed)
+	}
+	return nil
+}
+
+// transferRun is a running transfer. The checkpoint is read by the progress
+// bar while the transfer updates it.
+type transferRun struct {
+	s   *ShellContext
+	col driver.Collection
+	o   transferOptions
+
+	mu         sync.Mutex
+	checkpoint transferCheckpoint
+	// problems are reported once the transfer ends, as the progress bar
+	// owns the terminal while it runs
+	problems []recordError
+}
+
+func (t *transferRun) report(line int, format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.problems = append(t.problems, recordError{line: line, message: fmt.Sprintf(format, args...)})
+}
+
+func (t *transferRun) snapshot() transferCheckpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.checkpoint
+}
+
+// commit records a written batch and persists the checkpoint.
+func (t *transferRun) commit(update func(c *transferCheckpoint)) error {
+	t.mu.Lock()
+	update(&t.checkpoint)
+	t.checkpoint.Updated = time.Now().UTC()
+	c := t.checkpoint
+	t.mu.Unlock()
+
+	// Write to a temporary file first, so a crash never leaves a broken checkpoint
+	tmp := t.o.checkpoint + ".tmp"
+	if err := writeJSONFile(tmp, c); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.o.checkpoint)
+}
+
+// retry runs fn until it succeeds, waiting longer after every failed attempt.
+func (t *transferRun) retry(ctx context.Context, what string, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= t.o.retries; attempt++ {
+		if err = fn(); err == nil || ctx.Err() != nil {
+			return err
+		}
+		if attempt < t.o.retries {
+			wait := time.Duration(attempt) * time.Second
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return fmt.Errorf("%s failed after %d attempts: %v", what, t.o.retries, err)
+}
+
+// export reads the collection in _key order, so that a resumed export can
+// continue after the last key written.
+func (t *transferRun) export(ctx context.Context) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if t.o.resume {
+		flags = os.O_CREATE | os.O_WRONLY
+	}
+	file, err := os.OpenFile(t.o.file, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Drop whatever was written after the last committed batch
+	start := t.snapshot()
+	if err := file.Truncate(start.Offset); err != nil {
+		return err
+	}
+	if _, err := file.Seek(start.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	const query = "FOR doc IN @@collection FILTER doc._key > @last SORT doc._key LIMIT @limit RETURN doc"
+	lastKey, offset := start.LastKey, start.Offset
+	for {
+		// Documents are copied as the server sent them, so numbers keep their
+		// exact value; only the _key is decoded for the checkpoint
+		var docs []json.RawMessage
+		err := t.retry(ctx, "reading batch after key '"+lastKey+"'", func() error {
+			bindVars := map[string]interface{}{"@collection": t.col.Name(), "last": lastKey, "limit": t.o.batchSize}
+			cursor, err := t.s.DB.Query(driver.WithQueryBatchSize(ctx, t.o.batchSize), query, bindVars)
+			if err != nil {
+				return err
+			}
+			defer cursor.Close()
+			docs = docs[:0]
+			for {
+				var doc json.RawMessage
+				_, err := cursor.ReadDocument(ctx, &doc)
+				if driver.IsNoMoreDocuments(err) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				docs = append(docs, doc)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		var sb strings.Builder
+		for _, doc := range docs {
+			sb.Write(doc)
+			sb.WriteByte('\n')
+		}
+		n, err := file.WriteString(sb.String())
+		if err != nil {
+			return err
+		}
+		if err := file.Sync(); err != nil {
+			return err
+		}
+
+		last, err := documentKey(docs[len(docs)-1])
+		if err != nil {
+			return err
+		}
+		lastKey, offset = last, offset+int64(n)
+		err = t.commit(func(c *transferCheckpoint) {
+			c.LastKey = lastKey
+			c.Offset = offset
+			c.Processed += int64(len(docs))
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// importFile writes the file to the collection in batches. Documents replace
+// existing ones with the same _key, so repeating a batch after a failure
+// gives the same result.
+func (t *transferRun) importFile(ctx context.Context) error {
+	file, err := os.Open(t.o.file)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	start := t.snapshot()
+	if _, err := file.Seek(start.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(file)
+	line, offset := start.Line, start.Offset
+
+	options := &driver.ImportDocumentOptions{OnDuplicate: driver.ImportOnDuplicateReplace}
+	for {
+		var (
+			docs    []interface{}
+			lines   []int
+			skipped int64
+			lastKey string
+			atEOF   bool
+		)
+		for len(docs) < t.o.batchSize {
+			data, err := reader.ReadBytes('\n')
+			if err == io.EOF {
+				atEOF = true
+				if len(data) == 0 {
+					break
+				}
+			} else if err != nil {
+				return err
+			}
+			line++
+			offset += int64(len(data))
+
+			text := strings.TrimSpace(string(data))
+			if text == "" {
+				continue
+			}
+			// The line is sent as it is, so numbers keep their exact value
+			doc := json.RawMessage(text)
+			key, err := documentKey(doc)
+			if err != nil {
+				t.report(line, "skipped, invalid JSON: %v", err)
+				skipped++
+				continue
+			}
+			if key == "" {
+				// Without a key a repeated batch would create duplicates
+				t.report(line, "skipped, document has no _key")
+				skipped++
+				continue
+			}
+			docs = append(docs, doc)
+			lines = append(lines, line)
+			lastKey = key
+			if atEOF {
+				break
+			}
+		}
+		if atEOF && len(docs) == 0 && skipped == 0 {
+			return nil
+		}
+
+		var failed int64
+		if len(docs) > 0 {
+			err := t.retry(ctx, fmt.Sprintf("writing batch ending at line %d", line), func() error {
+				var details []string
+				stats, err := t.col.ImportDocuments(driver.WithImportDetails(ctx, &details), docs, options)
+				if err != nil {
+					return err
+				}
+				failed = stats.Errors
+				for _, detail := range details {
+					var position int
+					if _, err := fmt.Sscanf(detail, "at position %d:", &position); err == nil && position < len(lines) {
+						t.report(lines[position], "%s", strings.TrimSpace(detail[strings.Index(detail, ":")+1:]))
+					} else {
+						t.report(0, "%s", detail)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err := t.commit(func(c *transferCheckpoint) {
+			if lastKey != "" {
+				c.LastKey = lastKey
+			}
+			c.Offset = offset
+			c.Line = line
+			c.Processed += int64(len(docs)) - failed
+			c.Skipped += skipped
+			c.Failed += failed
+		})
+		if err != nil {
+			return err
+		}
+		if atEOF {
+			return nil
+		}
+	}
+}
+
+var transferCmd = newTransferCmd("transfer", connectFromFlags)
+
+func init() {
+	rootCmd.AddCommand(transferCmd)
+
+	// Keep stdout clean for scripts, so no banner here
+	transferCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+	addConnectionFlags(transferCmd.PersistentFlags())
+}
+
+// documentKey returns the _key of a JSON document without decoding the rest.
+func documentKey(doc json.RawMessage) (string, error) {
+	var meta struct {
+		Key string `json:"_key"`
+	}
+	if err := json.Unmarshal(doc, &meta); err != nil {
+		return "", err
+	}
+	return meta.Key, nil
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// treeNode is an object attribute or array element in the tree view. The
+// children of a node are only built when it is expanded the first time.
+type treeNode struct {
+	label    string
+	value    interface{}
+	depth    int
+	parent   *treeNode
+	children []*treeNode
+	built    bool
+	expanded bool
+}
+
+func (n *treeNode) container() bool {
+	switch v := n.value.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+func (n *treeNode) build() {
+	if n.built {
+		return
+	}
+	n.built = true
+	switch v := n.value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sortColumns(keys)
+		for _, key := range keys {
+			n.children = append(n.children, &treeNode{label: key, value: v[key], depth: n.depth + 1, parent: n})
+		}
+	case []interface{}:
+		for i, item := range v {
+			n.children = append(n.children, &treeNode{label: fmt.Sprintf("[%d]", i), value: item, depth: n.depth + 1, parent: n})
+		}
+	}
+}
+
+// expandTo expands the nodes above depth and collapses the rest.
+func (n *treeNode) expandTo(depth int) {
+	n.expanded = n.container() && n.depth < depth
+	if !n.expanded {
+		return
+	}
+	n.build()
+	for _, child := range n.children {
+		child.expandTo(depth)
+	}
+}
+
+// treeView shows documents as a tree of collapsible objects and arrays.
+type treeView struct {
+	roots []*treeNode
+	// rows are the nodes on screen, in order, below expanded nodes only
+	rows   []*treeNode
+	cursor *treeNode
+	offset int
+	width  int
+	height int
+}
+
+func newTreeView() *treeView {
+	return &treeView{}
+}
+
+// setDocuments adds a root node for each document not seen yet.
+func (v *treeView) setDocuments(docs []interface{}) {
+	for i := len(v.roots); i < len(docs); i++ {
+		v.roots = append(v.roots, &treeNode{label: fmt.Sprintf("[%d]", i), value: docs[i]})
+	}
+	v.refresh()
+}
+
+func (v *treeView) setSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.scroll()
+}
+
+// refresh rebuilds the rows after nodes were expanded or collapsed. If the
+// cursor ended up inside a collapsed node, it moves to that node.
+func (v *treeView) refresh() {
+	v.rows = v.rows[:0]
+	var walk func(nodes []*treeNode)
+	walk = func(nodes []*treeNode) {
+		for _, node := range nodes {
+			v.rows = append(v.rows, node)
+			if node.expanded {
+				walk(node.children)
+			}
+		}
+	}
+	walk(v.roots)
+
+	if v.cursor == nil && len(v.rows) > 0 {
+		v.cursor = v.rows[0]
+	}
+	for node := v.cursor; node != nil; node = node.parent {
+		if node.parent == nil || node.parent.expanded {
+			continue
+		}
+		v.cursor = node.parent
+	}
+	v.scroll()
+}
+
+func (v *treeView) cursorIndex() int {
+	for i, node := range v.rows {
+		if node == v.cursor {
+			return i
+		}
+	}
+	return 0
+}
+
+// scroll keeps the cursor on screen.
+func (v *treeView) scroll() {
+	index := v.cursorIndex()
+	if index < v.offset {
+		v.offset = index
+	}
+	if v.height > 0 && index >= v.offset+v.height {
+		v.offset = index - v.height + 1
+	}
+	v.offset = max(min(v.offset, len(v.rows)-v.height), 0)
+}
+
+func (v *treeView) moveCursor(step int) {
+	if len(v.rows) == 0 {
+		return
+	}
+	index := max(min(v.cursorIndex()+step, len(v.rows)-1), 0)
+	v.cursor = v.rows[index]
+	v.scroll()
+}
+
+func (v *treeView) update(msg tea.KeyMsg) {
+	node := v.cursor
+	if node == nil {
+		return
+	}
+	switch msg.String() {
+	case "up", "k":
+		v.moveCursor(-1)
+	case "down", "j":
+		v.moveCursor(1)
+	case "pgup", "ctrl+u":
+		v.moveCursor(-v.height)
+	case "pgdown", "ctrl+d", " ":
+		v.moveCursor(v.height)
+	case "home", "g":
+		v.moveCursor(-len(v.rows))
+	case "end", "G":
+		v.moveCursor(len(v.rows))
+	case "right", "l":
+		// Expand, or step into a node that is already expanded
+		if node.expanded {
+			v.moveCursor(1)
+		} else if node.container() {
+			node.build()
+			node.expanded = true
+			v.refresh()
+		}
+	case "left", "h":
+		// Collapse, or step out to the parent
+		if node.expanded {
+			node.expanded = false
+			v.refresh()
+		} else if node.parent != nil {
+			v.cursor = node.parent
+			v.scroll()
+		}
+	case "enter":
+		if node.container() {
+			node.build()
+			node.expanded = !node.expanded
+			v.refresh()
+		}
+	case "0":
+		for _, root := range v.roots {
+			root.expandTo(0)
+		}
+		v.refresh()
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		depth := int(msg.String()[0] - '0')
+		for _, root := range v.roots {
+			root.expandTo(depth)
+		}
+		v.refresh()
+	}
+}
+
+// selection returns the value under the cursor and the document it belongs to.
+func (v *treeView) selection() (interface{}, interface{}) {
+	if v.cursor == nil {
+		return nil, nil
+	}
+	root := v.cursor
+	for root.parent != nil {
+		root = root.parent
+	}
+	return v.cursor.value, root.value
+}
+
+// nearEnd reports whether the cursor is within a page of the last row, so
+// the viewer should load more documents.
+func (v *treeView) nearEnd() bool {
+	return len(v.rows)-v.cursorIndex() < v.height
+}
+
+func (v *treeView) view() string {
+	t := currentTheme()
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+
+	lines := make([]string, 0, v.height)
+	for i := v.offset; i < len(v.rows) && i < v.offset+v.height; i++ {
+		node := v.rows[i]
+		marker := "  "
+		if node.container() {
+			marker = "▸ "
+			if node.expanded {
+				marker = "▾ "
+			}
+		}
+		prefix := strings.Repeat("  ", node.depth) + marker + node.label + ": "
+		summary := summarizeValue(node.value, node.expanded)
+		// Truncate on the plain text, the colors are added afterwards
+		room := max(v.width-runewidth.StringWidth(prefix), 1)
+		summary = runewidth.Truncate(summary, room, "…")
+
+		if node == v.cursor {
+			lines = append(lines, selected.Render(prefix+summary))
+			continue
+		}
+		if node.container() {
+			summary = t.Comment.paint(summary)
+		} else {
+			summary = colorizeJSON(summary, t)
+		}
+		lines = append(lines, prefix+summary)
+	}
+	for len(lines) < v.height {
+		lines = append(lines, "")
+	}
+	return lipgloss.NewStyle().Width(v.width).Render(strings.Join(lines, "\n"))
+}
+
+// summarizeValue describes a value on one line. Containers show their type
+// and size instead of their content.
+func summarizeValue(value interface{}, expanded bool) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		summary := fmt.Sprintf("object, %d %s", len(v), plural(len(v), "key"))
+		if expanded {
+			return summary
+		}
+		return "{…} " + summary + documentHint(v)
+	case []interface{}:
+		summary := fmt.Sprintf("array, %d %s", len(v), plural(len(v), "item"))
+		if expanded {
+			return summary
+		}
+		return "[…] " + summary + arrayTypes(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// documentHint names a collapsed document by its _id, and an edge also by
+// the documents it connects.
+func documentHint(doc map[string]interface{}) string {
+	hint := ""
+	if id, ok := doc["_id"].(string); ok {
+		hint = " " + id
+	}
+	from, fromOK := doc["_from"].(string)
+	to, toOK := doc["_to"].(string)
+	if fromOK && toOK {
+		hint += fmt.Sprintf(" (%s → %s)", from, to)
+	}
+	return hint
+}
+
+// arrayTypes lists the types of the elements of a collapsed array.
+func arrayTypes(items []interface{}) string {
+	seen := map[string]bool{}
+	for _, item := range items {
+		seen[typeName(item)] = true
+	}
+	types := make([]string, 0, len(seen))
+	for name := range seen {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return " of " + strings.Join(types, ", ")
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
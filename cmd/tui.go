@@ -0,0 +1,640 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/thakurankit7/arango-cli/aql"
+)
+
+// sidebarWidth is the width of the database tree including its border.
+const sidebarWidth = 30
+
+type workbenchPane int
+
+const (
+	sidebarPane workbenchPane = iota
+	editorPane
+	resultsPane
+)
+
+func (p workbenchPane) String() string {
+	switch p {
+	case sidebarPane:
+		return "Databases"
+	case editorPane:
+		return "Editor"
+	}
+	return "Results"
+}
+
+// sidebarNode is an entry of the database tree: a database, a group such as
+// "Collections", or a collection, view or graph.
+type sidebarNode struct {
+	label    string
+	kind     string
+	database string
+	expanded bool
+	loading  bool
+	children []*sidebarNode
+}
+
+// sidebarRow is a visible node of the tree with its indentation.
+type sidebarRow struct {
+	node  *sidebarNode
+	depth int
+}
+
+// workbenchBatchMsg is a batch of the result of query run.
+type workbenchBatchMsg struct {
+	run   int
+	batch batchMsg
+}
+
+type databasesMsg struct {
+	names []string
+	err   error
+}
+
+type catalogMsg struct {
+	database string
+	catalog  *schemaCatalog
+}
+
+// queryStartedMsg carries the cursor of a query started from the editor.
+// run identifies the query, messages of a cancelled query are dropped.
+type queryStartedMsg struct {
+	run      int
+	ctx      context.Context
+	query    string
+	cursor   driver.Cursor
+	err      error
+	started  time.Time
+	duration time.Duration
+}
+
+// workbenchModel is the full-screen TUI: a database tree, an AQL editor and
+// a result pane, with a status bar. It works on a copy of the shell
+// session, so changing the database here does not affect the shell.
+type workbenchModel struct {
+	s       *ShellContext
+	focus   workbenchPane
+	tree    []*sidebarNode
+	cursor  int
+	offset  int
+	editor  textarea.Model
+	results viewport.Model
+	stream  *resultStream
+	cancel  context.CancelFunc
+	run     int
+	content string
+	// wrapped is content wrapped to the width of the result pane
+	wrapped string
+	status  string
+	width   int
+	height  int
+	ready   bool
+}
+
+func newWorkbenchModel(s *ShellContext) workbenchModel {
+	session := *s
+	editor := textarea.New()
+	editor.CharLimit = 0
+	editor.Placeholder = "FOR doc IN collection RETURN doc"
+	editor.Focus()
+
+	return workbenchModel{
+		s:       &session,
+		focus:   editorPane,
+		editor:  editor,
+		content: "Write a query and press Ctrl-R to run it.",
+		status:  "Loading databases...",
+	}
+}
+
+func (m workbenchModel) Init() tea.Cmd {
+	return tea.Batch(textarea.Blink, m.loadDatabases())
+}
+
+func (m workbenchModel) loadDatabases() tea.Cmd {
+	s := m.s
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(s.Context, 10*time.Second)
+		defer cancel()
+		dbs, err := s.Client.AccessibleDatabases(ctx)
+		if err != nil {
+			return databasesMsg{err: err}
+		}
+		names := make([]string, 0, len(dbs))
+		for _, db := range dbs {
+			names = append(names, db.Name())
+		}
+		sort.Strings(names)
+		return databasesMsg{names: names}
+	}
+}
+
+// loadCatalog reads the collections, views and graphs of a database, reusing
+// the loader of the completion cache.
+func (m workbenchModel) loadCatalog(database string) tea.Cmd {
+	s := m.s
+	return func() tea.Msg {
+		session := *s
+		if database != s.CurrentDB {
+			db, err := s.Client.Database(s.Context, database)
+			if err != nil {
+				return catalogMsg{database: database, catalog: &schemaCatalog{}}
+			}
+			session.DB, session.CurrentDB = db, database
+		}
+		return catalogMsg{database: database, catalog: session.loadCatalog()}
+	}
+}
+
+func (m workbenchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		m.ready = true
+		return m, nil
+
+	case databasesMsg:
+		if msg.err != nil {
+			// Users without access to the database list still see their own
+			msg.names = []string{m.s.CurrentDB}
+		}
+		m.tree = nil
+		for _, name := range msg.names {
+			m.tree = append(m.tree, &sidebarNode{label: name, kind: "database", database: name})
+		}
+		m.status = ""
+		for _, node := range m.tree {
+			if node.database == m.s.CurrentDB {
+				node.expanded, node.loading = true, true
+				return m, m.loadCatalog(node.database)
+			}
+		}
+		return m, nil
+
+	case catalogMsg:
+		for _, node := range m.tree {
+			if node.database == msg.database {
+				node.loading = false
+				node.children = catalogNodes(msg.database, msg.catalog)
+			}
+		}
+		return m, nil
+
+	case queryStartedMsg:
+		if msg.run != m.run {
+			if msg.cursor != nil {
+				msg.cursor.Close()
+			}
+			return m, nil
+		}
+		entry := historyEntry{Time: msg.started, Query: msg.query, Status: historyOK, DurationMs: msg.duration.Milliseconds()}
+		if msg.err != nil {
+			entry.Status, entry.Error = historyFailed, msg.err.Error()
+			m.s.History.add(entry)
+			m.cancel = nil
+			m.setContent(fmt.Sprintf("Error: %v", msg.err))
+			m.status = "Query failed"
+			return m, nil
+		}
+		m.s.History.add(entry)
+		var formatter ResultFormatter
+		if m.s.Format != defaultShellFormat {
+			formatter, _ = getFormatter(m.s.Format)
+		}
+		m.stream = newResultStream(msg.ctx, msg.cursor, m.s.Settings.BatchSize, resultRenderer(formatter, msg.cursor.Statistics()))
+		m.setContent("")
+		m.status = fmt.Sprintf("Query started in %v", msg.duration.Round(time.Millisecond))
+		return m, m.fetchBatch()
+
+	case workbenchBatchMsg:
+		if m.stream == nil || msg.run != m.run {
+			return m, nil
+		}
+		m.appendContent(m.stream.apply(msg.batch))
+		m.status = fmt.Sprintf("%s documents", m.stream.progress())
+		if m.stream.done {
+			m.stream.close()
+			m.cancel = nil
+		}
+		if m.stream.eager() {
+			return m, m.fetchBatch()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+q":
+			m.stop()
+			return m, tea.Quit
+		case "ctrl+c":
+			if m.cancel != nil {
+				m.stop()
+				m.status = "Query cancelled"
+				return m, nil
+			}
+			return m, tea.Quit
+		case "tab":
+			m.setFocus((m.focus + 1) % 3)
+			return m, nil
+		case "shift+tab":
+			m.setFocus((m.focus + 2) % 3)
+			return m, nil
+		case "ctrl+r", "f5":
+			return m, m.runQuery()
+		}
+
+		switch m.focus {
+		case sidebarPane:
+			return m, m.updateSidebar(msg)
+		case editorPane:
+			var cmd tea.Cmd
+			m.editor, cmd = m.editor.Update(msg)
+			return m, cmd
+		case resultsPane:
+			var cmd tea.Cmd
+			m.results, cmd = m.results.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	if m.focus == editorPane {
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	// Load the next batch once the user gets within a page of the end
+	if m.stream != nil && m.ready &&
+		m.results.TotalLineCount()-(m.results.YOffset+m.results.Height) < m.results.Height {
+		cmds = append(cmds, m.fetchBatch())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// fetchBatch reads the next batch of the running query, tagged with its run.
+func (m workbenchModel) fetchBatch() tea.Cmd {
+	fetch := m.stream.fetchBatch()
+	if fetch == nil {
+		return nil
+	}
+	run := m.run
+	return func() tea.Msg {
+		return workbenchBatchMsg{run: run, batch: fetch().(batchMsg)}
+	}
+}
+
+// layout sizes the panes to the window.
+func (m *workbenchModel) layout() {
+	mainWidth := max(m.width-sidebarWidth, 20)
+	editorHeight := max(m.height/3, 5)
+	resultsHeight := max(m.height-editorHeight-1, 3)
+
+	// Borders take two columns and rows
+	m.editor.SetWidth(mainWidth - 2)
+	m.editor.SetHeight(editorHeight - 2)
+	if !m.ready {
+		m.results = viewport.New(mainWidth-2, resultsHeight-2)
+	} else {
+		m.results.Width, m.results.Height = mainWidth-2, resultsHeight-2
+	}
+	m.setContent(m.content)
+}
+
+// setContent replaces the text of the result pane.
+func (m *workbenchModel) setContent(text string) {
+	m.content = text
+	m.wrapped = wordWrap(text, m.results.Width)
+	m.results.SetContent(m.wrapped)
+}
+
+// appendContent adds text to the end of the result pane. Only the new text
+// is wrapped, the content before it ends with a line break.
+func (m *workbenchModel) appendContent(text string) {
+	m.content += text
+	m.wrapped += wordWrap(text, m.results.Width)
+	m.results.SetContent(m.wrapped)
+}
+
+func (m *workbenchModel) setFocus(pane workbenchPane) {
+	m.focus = pane
+	if pane == editorPane {
+		m.editor.Focus()
+	} else {
+		m.editor.Blur()
+	}
+}
+
+// stop cancels the running query, if any.
+func (m *workbenchModel) stop() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	if m.stream != nil && !m.stream.done {
+		m.stream.close()
+		m.stream = nil
+	}
+}
+
+// runQuery starts the statement at the editor's cursor.
+func (m *workbenchModel) runQuery() tea.Cmd {
+	query := aql.StatementAt(m.editor.Value(), m.editorOffset())
+	if query == "" {
+		m.status = "Nothing to run"
+		return nil
+	}
+	bindVars, missing := selectBindVars(query, m.s.BindVars)
+	if len(missing) > 0 {
+		m.status = fmt.Sprintf("Missing bind parameters: @%s (set them with /set in the shell)", strings.Join(missing, ", @"))
+		return nil
+	}
+
+	m.stop()
+	m.run++
+	ctx, cancel := context.WithCancel(m.s.Context)
+	m.cancel = cancel
+	m.status = "Running..."
+	m.setContent("Running...")
+	m.results.GotoTop()
+
+	s, run := m.s, m.run
+	return func() tea.Msg {
+		started := time.Now()
+		cursor, err := s.openCursor(ctx, query, bindVars)
+		return queryStartedMsg{run: run, ctx: ctx, query: query, cursor: cursor, err: err, started: started, duration: time.Since(started)}
+	}
+}
+
+// editorOffset returns the byte offset of the cursor in the editor text.
+func (m workbenchModel) editorOffset() int {
+	lines := strings.Split(m.editor.Value(), "\n")
+	row := min(m.editor.Line(), len(lines)-1)
+	offset := 0
+	for _, line := range lines[:row] {
+		offset += len(line) + 1
+	}
+	info := m.editor.LineInfo()
+	runes := []rune(lines[row])
+	column := min(info.StartColumn+info.ColumnOffset, len(runes))
+	return offset + len(string(runes[:column]))
+}
+
+// visibleRows flattens the expanded part of the tree.
+func (m workbenchModel) visibleRows() []sidebarRow {
+	var rows []sidebarRow
+	var walk func(nodes []*sidebarNode, depth int)
+	walk = func(nodes []*sidebarNode, depth int) {
+		for _, node := range nodes {
+			rows = append(rows, sidebarRow{node: node, depth: depth})
+			if node.expanded {
+				walk(node.children, depth+1)
+			}
+		}
+	}
+	walk(m.tree, 0)
+	return rows
+}
+
+func (m *workbenchModel) updateSidebar(msg tea.KeyMsg) tea.Cmd {
+	rows := m.visibleRows()
+	if len(rows) == 0 {
+		return nil
+	}
+	m.cursor = min(m.cursor, len(rows)-1)
+	node := rows[m.cursor].node
+
+	switch msg.String() {
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(rows)-1)
+	case "left", "h":
+		if node.expanded {
+			node.expanded = false
+		} else {
+			// Jump to the parent
+			for i := m.cursor - 1; i >= 0; i-- {
+				if rows[i].depth < rows[m.cursor].depth {
+					m.cursor = i
+					break
+				}
+			}
+		}
+	case "right", "l", "enter", " ":
+		// Right expands, Enter and Space toggle. Enter on a database also
+		// makes it the one queries run in.
+		expand := msg.String() == "right" || msg.String() == "l"
+		switch node.kind {
+		case "database":
+			if msg.String() == "enter" {
+				m.useDatabase(node.database)
+			}
+			node.expanded = expand || !node.expanded
+			if node.expanded && node.children == nil && !node.loading {
+				node.loading = true
+				return m.loadCatalog(node.database)
+			}
+		case "group":
+			node.expanded = expand || !node.expanded
+		default:
+			if msg.String() == "enter" {
+				m.useDatabase(node.database)
+				m.editor.SetValue(queryTemplate(node))
+				m.setFocus(editorPane)
+			}
+		}
+	}
+
+	// Keep the cursor on screen
+	height := m.height - 3
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	return nil
+}
+
+// useDatabase makes queries of the workbench run in database.
+func (m *workbenchModel) useDatabase(database string) {
+	if database == m.s.CurrentDB {
+		return
+	}
+	db, err := m.s.Client.Database(m.s.Context, database)
+	if err != nil {
+		m.status = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	// A transaction of the shell belongs to its database
+	m.s.DB, m.s.CurrentDB, m.s.Transaction = db, database, ""
+	m.status = fmt.Sprintf("Using database '%s'", database)
+}
+
+// queryTemplate returns a starting query for a collection, view or graph.
+func queryTemplate(node *sidebarNode) string {
+	switch node.kind {
+	case "graph":
+		return fmt.Sprintf("FOR v, e, p IN 1..1 ANY @start GRAPH '%s'\n  LIMIT 100\n  RETURN p", node.label)
+	case "view":
+		return fmt.Sprintf("FOR doc IN %s\n  SEARCH true\n  LIMIT 100\n  RETURN doc", node.label)
+	}
+	return fmt.Sprintf("FOR doc IN %s\n  LIMIT 100\n  RETURN doc", node.label)
+}
+
+// catalogNodes builds the groups shown below a database.
+func catalogNodes(database string, catalog *schemaCatalog) []*sidebarNode {
+	var groups []*sidebarNode
+	for _, group := range []struct {
+		label string
+		kind  string
+		names []string
+	}{
+		{"Collections", "collection", catalog.collections},
+		{"Views", "view", catalog.views},
+		{"Graphs", "graph", catalog.graphs},
+	} {
+		node := &sidebarNode{
+			label:    fmt.Sprintf("%s (%d)", group.label, len(group.names)),
+			kind:     "group",
+			database: database,
+			expanded: group.kind == "collection",
+		}
+		for _, name := range group.names {
+			node.children = append(node.children, &sidebarNode{label: name, kind: group.kind, database: database})
+		}
+		groups = append(groups, node)
+	}
+	return groups
+}
+
+func (m workbenchModel) View() string {
+	if !m.ready {
+		return "Initializing..."
+	}
+
+	pane := func(focused bool) lipgloss.Style {
+		style := lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("62"))
+		if focused {
+			style = style.BorderForeground(lipgloss.Color("170"))
+		}
+		return style
+	}
+
+	sidebarHeight := m.height - 3
+	var lines []string
+	rows := m.visibleRows()
+	for i := m.offset; i < len(rows) && i < m.offset+sidebarHeight; i++ {
+		row := rows[i]
+		marker := "  "
+		if row.node.kind == "database" || row.node.kind == "group" {
+			marker = "▸ "
+			if row.node.expanded {
+				marker = "▾ "
+			}
+		}
+		label := row.node.label
+		if row.node.loading {
+			label += " …"
+		}
+		line := []rune(strings.Repeat("  ", row.depth) + marker + label)
+		if len(line) > sidebarWidth-2 {
+			line = append(line[:sidebarWidth-3], '…')
+		}
+		text := string(line)
+		if row.node.kind == "database" && row.node.database == m.s.CurrentDB {
+			text = lipgloss.NewStyle().Bold(true).Render(text)
+		}
+		if i == m.cursor && m.focus == sidebarPane {
+			text = lipgloss.NewStyle().Reverse(true).Render(text)
+		}
+		lines = append(lines, text)
+	}
+	sidebar := pane(m.focus == sidebarPane).
+		Width(sidebarWidth - 2).Height(sidebarHeight).MaxHeight(sidebarHeight + 2).
+		Render(strings.Join(lines, "\n"))
+
+	editor := pane(m.focus == editorPane).Render(m.editor.View())
+	results := pane(m.focus == resultsPane).Render(m.results.View())
+	main := lipgloss.JoinVertical(lipgloss.Left, editor, results)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top, sidebar, main),
+		m.statusBar())
+}
+
+// statusBar shows the connection, the focused pane and the last message.
+func (m workbenchModel) statusBar() string {
+	parts := []string{
+		fmt.Sprintf("%s@%s", m.s.Config.Username, strings.TrimPrefix(strings.TrimPrefix(m.s.ConnectionURL, "http://"), "https://")),
+		"db: " + m.s.CurrentDB,
+	}
+	if m.s.CurrentConfig != "manual" {
+		parts = append([]string{"config: " + m.s.CurrentConfig}, parts...)
+	}
+	if m.s.Transaction != "" {
+		parts = append(parts, "trx: "+string(m.s.Transaction))
+	}
+	parts = append(parts, "focus: "+m.focus.String())
+	if m.status != "" {
+		parts = append(parts, m.status)
+	}
+	parts = append(parts, "Tab: pane • Ctrl-R: run • Ctrl-Q: quit")
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Background(lipgloss.Color("62")).
+		Width(m.width).MaxHeight(1).
+		Render(" " + strings.Join(parts, " │ "))
+}
+
+// runWorkbench shows the workbench until the user quits.
+func (s *ShellContext) runWorkbench() error {
+	prog := tea.NewProgram(newWorkbenchModel(s), tea.WithAltScreen())
+	model, err := prog.Run()
+	if m, ok := model.(workbenchModel); ok {
+		m.stop()
+	}
+	return err
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Open the full-screen workbench",
+	Long: `Open a full-screen workbench with a tree of databases, collections, views
+and graphs, an AQL editor and a result pane.
+
+Tab and Shift-Tab move between the panes. In the editor, Ctrl-R or F5 runs
+the statement under the cursor, Ctrl-C cancels a running query and Ctrl-Q
+quits. In the tree, Enter on a collection, view or graph starts a query on it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := connectFromFlags()
+		if err != nil {
+			return err
+		}
+		return s.runWorkbench()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {}
+	addConnectionFlags(tuiCmd.Flags())
+}
@@ -12,16 +12,54 @@ import (
 )
 
 type popupModel struct {
-	content      string
+	title   string
+	content string
+	// wrapped is content wrapped to the viewport width
+	wrapped      string
 	viewport     viewport.Model
 	width        int
 	height       int
 	windowWidth  int
 	windowHeight int
 	ready        bool
+	results      *resultStream
+	// docs are the documents behind content when they aren't streamed
+	docs []interface{}
+	// table is the tabular mode toggled with t, nil while showing text
+	table *tableView
+	// tree is the tree mode toggled with v. It is kept while hidden, so
+	// switching back restores the expanded nodes and the cursor.
+	tree     *treeView
+	showTree bool
+	// search highlights the matches of / in the text mode
+	search *viewerSearch
+
+	// session fetches the documents of followed links, nil if the viewer
+	// can't follow links
+	session *ShellContext
+	// stack holds the views to go back to from a followed link
+	stack []viewerFrame
+	// status reports loading and errors of followed links in the footer
+	status string
+}
+
+// viewerFrame is the state of a view on the viewer's stack.
+type viewerFrame struct {
+	title    string
+	content  string
+	results  *resultStream
+	docs     []interface{}
+	table    *tableView
+	tree     *treeView
+	showTree bool
+	search   *viewerSearch
+	yOffset  int
 }
 
 func (m popupModel) Init() tea.Cmd {
+	if m.results != nil {
+		return m.results.fetchBatch()
+	}
 	return nil
 }
 
@@ -71,9 +109,126 @@ func (m popupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		// Typing a filter needs q and Esc
+		if m.table != nil && m.table.editing {
+			return m, m.table.update(msg)
+		}
+		if m.search != nil && m.search.editing {
+			cmd = m.search.update(msg)
+			m.showContent()
+			m.scrollToMatch()
+			return m, cmd
+		}
+		// The first Esc clears the search, the second one closes the viewer
+		if msg.String() == "esc" && m.textMode() && m.search.active() {
+			m.search = nil
+			m.showContent()
+			return m, nil
+		}
 		if msg.String() == "q" || msg.String() == "esc" {
 			return m, tea.Quit
 		}
+		if m.session != nil {
+			switch msg.String() {
+			case "enter":
+				// Enter on anything but a handle toggles tree nodes
+				if handle, ok := documentHandle(m.selection()); ok {
+					m.status = fmt.Sprintf("Loading %s...", handle)
+					return m, m.session.fetchDocument(handle)
+				}
+			case "e":
+				if id, ok := m.currentDocument(); ok {
+					m.status = fmt.Sprintf("Loading edges of %s...", id)
+					return m, m.session.fetchEdges(id)
+				}
+				m.status = "Move the cursor onto a document with an _id to list its edges"
+				return m, nil
+			case "backspace":
+				m.back()
+				return m, nil
+			}
+		}
+		if msg.String() == "t" && m.ready && m.documents() != nil {
+			m.toggleTable()
+			return m, nil
+		}
+		if msg.String() == "v" && m.ready && m.documents() != nil {
+			m.toggleTree()
+			return m, nil
+		}
+		if m.showTree {
+			m.tree.update(msg)
+			if m.results != nil && m.tree.nearEnd() {
+				cmds = append(cmds, m.results.fetchBatch())
+			}
+			return m, tea.Batch(cmds...)
+		}
+		if m.table != nil {
+			cmds = append(cmds, m.table.update(msg))
+			if m.results != nil && m.table.nearEnd() {
+				cmds = append(cmds, m.results.fetchBatch())
+			}
+			return m, tea.Batch(cmds...)
+		}
+		switch msg.String() {
+		case "/":
+			if m.search == nil {
+				m.search = newViewerSearch()
+			}
+			return m, m.search.start()
+		case "n", "N":
+			if m.search.active() {
+				if msg.String() == "n" {
+					m.search.step(1)
+				} else {
+					m.search.step(-1)
+				}
+				m.showContent()
+				m.scrollToMatch()
+			}
+			return m, nil
+		}
+
+	case linkMsg:
+		m.status = ""
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		m.open(msg)
+
+	case batchMsg:
+		// The stream may belong to a view further down the stack
+		if m.results == nil {
+			for i := range m.stack {
+				if frame := &m.stack[i]; frame.results != nil {
+					frame.content += frame.results.apply(msg)
+					if frame.results.eager() {
+						cmds = append(cmds, frame.results.fetchBatch())
+					}
+					if frame.table != nil {
+						frame.table.setDocuments(frame.results.docs)
+					}
+					if frame.tree != nil {
+						frame.tree.setDocuments(frame.results.docs)
+					}
+				}
+			}
+			break
+		}
+		m.appendContent(m.results.apply(msg))
+		if m.results.eager() {
+			cmds = append(cmds, m.results.fetchBatch())
+		}
+		if m.table != nil {
+			m.table.setDocuments(m.results.docs)
+		}
+		if m.tree != nil {
+			m.tree.setDocuments(m.results.docs)
+		}
 
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
@@ -87,23 +242,204 @@ func (m popupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			contentHeight := m.height - 8 // space for header, footer, and padding
 
 			m.viewport = viewport.New(contentWidth, contentHeight)
-			m.viewport.SetContent(wordWrap(m.content, contentWidth))
+			m.setContent()
 			m.ready = true
 		} else {
 			contentWidth := m.width - 4
 			contentHeight := m.height - 8
 			m.viewport.Width = contentWidth
 			m.viewport.Height = contentHeight
-			m.viewport.SetContent(wordWrap(m.content, contentWidth))
+			m.setContent()
+		}
+		if m.table != nil {
+			m.table.setSize(m.viewport.Width, m.viewport.Height)
+		}
+		if m.tree != nil {
+			m.tree.setSize(m.viewport.Width, m.viewport.Height)
 		}
 	}
 
+	if m.table != nil || m.showTree {
+		return m, tea.Batch(cmds...)
+	}
+
 	m.viewport, cmd = m.viewport.Update(msg)
 	cmds = append(cmds, cmd)
 
+	// Load the next batch once the user gets within a page of the end
+	if m.results != nil && m.ready &&
+		m.viewport.TotalLineCount()-(m.viewport.YOffset+m.viewport.Height) < m.viewport.Height {
+		cmds = append(cmds, m.results.fetchBatch())
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// setContent wraps the content to the viewport and highlights the matches of
+// the search, if any.
+func (m *popupModel) setContent() {
+	m.wrapped = wordWrap(m.content, m.viewport.Width)
+	m.showContent()
+}
+
+// appendContent adds text to the end of the content. Only the new text is
+// wrapped, the content before it ends with a line break.
+func (m *popupModel) appendContent(text string) {
+	m.content += text
+	if m.ready {
+		m.wrapped += wordWrap(text, m.viewport.Width)
+		m.showContent()
+	}
+}
+
+// showContent puts the wrapped content into the viewport, with the matches
+// of the search highlighted.
+func (m *popupModel) showContent() {
+	if !m.search.active() {
+		m.viewport.SetContent(m.wrapped)
+		return
+	}
+	plain := stripLines(m.wrapped)
+	m.search.find(plain)
+	m.viewport.SetContent(m.search.highlight(strings.Split(m.wrapped, "\n"), plain))
+}
+
+// scrollToMatch scrolls the current match into the middle of the viewport
+// unless it is already visible.
+func (m *popupModel) scrollToMatch() {
+	if !m.search.active() || len(m.search.matches) == 0 {
+		return
+	}
+	line := m.search.matches[m.search.current].line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height/2)
+	}
+}
+
+// textMode reports whether the viewer shows the text rather than the table
+// or the tree.
+func (m popupModel) textMode() bool {
+	return m.table == nil && !m.showTree
+}
+
+// selection returns the value under the cursor of the table or tree.
+func (m popupModel) selection() interface{} {
+	var value interface{}
+	switch {
+	case m.showTree:
+		value, _ = m.tree.selection()
+	case m.table != nil:
+		value, _ = m.table.selection()
+	}
+	return value
+}
+
+// currentDocument returns the _id of the document under the cursor, or of
+// the only document shown.
+func (m popupModel) currentDocument() (string, bool) {
+	var doc interface{}
+	switch {
+	case m.showTree:
+		_, doc = m.tree.selection()
+	case m.table != nil:
+		_, doc = m.table.selection()
+	case len(m.documents()) == 1:
+		doc = m.documents()[0]
+	}
+	return documentID(doc)
+}
+
+// open pushes the current view on the stack and shows the documents of a
+// followed link as a tree.
+func (m *popupModel) open(msg linkMsg) {
+	m.stack = append(m.stack, viewerFrame{
+		title:    m.title,
+		content:  m.content,
+		results:  m.results,
+		docs:     m.docs,
+		table:    m.table,
+		tree:     m.tree,
+		showTree: m.showTree,
+		search:   m.search,
+		yOffset:  m.viewport.YOffset,
+	})
+
+	m.title = msg.title
+	m.docs = append([]interface{}{}, msg.docs...)
+	m.content = "📊 Results:\n\n" + formatJSONArray(m.docs)
+	m.results, m.table, m.search = nil, nil, nil
+	m.tree = newTreeView()
+	m.tree.setSize(m.viewport.Width, m.viewport.Height)
+	m.tree.setDocuments(m.docs)
+	for _, root := range m.tree.roots {
+		root.expandTo(msg.depth)
+	}
+	m.tree.refresh()
+	m.showTree = true
+	m.setContent()
+	m.viewport.GotoTop()
+}
+
+// back returns to the view below the current one on the stack.
+func (m *popupModel) back() {
+	if len(m.stack) == 0 {
+		return
+	}
+	frame := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+
+	m.title, m.content = frame.title, frame.content
+	m.results, m.docs = frame.results, frame.docs
+	m.table, m.tree, m.showTree = frame.table, frame.tree, frame.showTree
+	m.search = frame.search
+	m.status = ""
+	// The window may have been resized meanwhile
+	if m.table != nil {
+		m.table.setSize(m.viewport.Width, m.viewport.Height)
+	}
+	if m.tree != nil {
+		m.tree.setSize(m.viewport.Width, m.viewport.Height)
+	}
+	m.setContent()
+	m.viewport.SetYOffset(frame.yOffset)
+}
+
+// documents returns the documents shown by the viewer, or nil if it shows
+// plain text like an execution plan.
+func (m popupModel) documents() []interface{} {
+	if m.results != nil {
+		return m.results.docs
+	}
+	return m.docs
+}
+
+// toggleTable switches between the text and the table mode. The table is
+// built anew each time, so it always reflects the documents loaded so far.
+func (m *popupModel) toggleTable() {
+	if m.table != nil {
+		m.table = nil
+		return
+	}
+	m.showTree = false
+	m.table = newTableView()
+	m.table.setSize(m.viewport.Width, m.viewport.Height)
+	m.table.setDocuments(m.documents())
+}
+
+// toggleTree switches between the text and the tree mode.
+func (m *popupModel) toggleTree() {
+	m.showTree = !m.showTree
+	if !m.showTree {
+		return
+	}
+	m.table = nil
+	if m.tree == nil {
+		m.tree = newTreeView()
+		m.tree.setSize(m.viewport.Width, m.viewport.Height)
+	}
+	m.tree.setDocuments(m.documents())
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -132,8 +468,49 @@ func (m popupModel) View() string {
 		Align(lipgloss.Center).
 		Width(m.width - 4)
 
-	header := headerStyle.Render("Query Results")
-	footer := footerStyle.Render("↑/↓: Scroll • q/ESC: Close")
+	title := m.title
+	if m.results != nil {
+		title = fmt.Sprintf("%s (%s)", m.title, m.results.progress())
+	}
+	header := headerStyle.Render(title)
+	help := "↑/↓: Scroll • /: Search • q/ESC: Close"
+	if m.documents() != nil {
+		help = "↑/↓: Scroll • /: Search • t: Table • v: Tree • q: Close"
+		if m.session != nil {
+			// Links are followed from the tree and the table only
+			help = "↑/↓: Scroll • /: Search • t: Table • v: Tree to follow links • q: Close"
+		}
+	}
+	if m.search != nil && (m.search.active() || m.search.err != nil) {
+		help = m.search.status() + " • n/N: Next/Prev • ESC: Clear"
+	}
+	body := m.viewport.View()
+	if m.showTree {
+		help = "←/→: Collapse/Expand • 1-9/0: Depth • v: Text • q: Close"
+		body = m.tree.view()
+	}
+	if m.table != nil {
+		help = "s: Sort • f: Filter • x/a: Hide/Show • </>: Move • t: Text • q: Close"
+		if m.table.editing {
+			help = "Enter: Apply • ESC: Cancel"
+		}
+		body = m.table.view()
+	}
+	if m.session != nil && !(m.table != nil && m.table.editing) {
+		if handle, ok := documentHandle(m.selection()); ok {
+			help = fmt.Sprintf("Enter: Open %s • e: Edges", handle)
+		}
+		if len(m.stack) > 0 {
+			help = "⌫: Back • " + help
+		}
+	}
+	if m.status != "" {
+		help = m.status
+	}
+	footer := footerStyle.Render(help)
+	if m.textMode() && m.search != nil && m.search.editing {
+		footer = lipgloss.NewStyle().Width(m.width - 4).Render(m.search.input.View())
+	}
 
 	separator := strings.Repeat("─", m.width-4)
 
@@ -141,7 +518,7 @@ func (m popupModel) View() string {
 		lipgloss.Center,
 		header,
 		separator,
-		m.viewport.View(),
+		body,
 		separator,
 		footer,
 	)
@@ -178,7 +555,30 @@ func (m popupModel) View() string {
 }
 
 func ShowPopup(content string) error {
-	p := popupModel{content: content}
+	return ShowPopupWithTitle("Query Results", content)
+}
+
+func ShowPopupWithTitle(title, content string) error {
+	p := popupModel{title: title, content: content}
+	prog := tea.NewProgram(p, tea.WithAltScreen())
+	_, err := prog.Run()
+	return err
+}
+
+// ShowDocuments shows content in the viewer, with docs available for the
+// table and tree modes. Links in the documents are followed through session.
+func ShowDocuments(session *ShellContext, title, content string, docs []interface{}) error {
+	p := popupModel{title: title, content: content, docs: docs, session: session}
+	prog := tea.NewProgram(p, tea.WithAltScreen())
+	_, err := prog.Run()
+	return err
+}
+
+// ShowResultStream shows the viewer and loads the documents of results lazily
+// while the user scrolls. Links in the documents are followed through session.
+// The caller closes results afterwards.
+func ShowResultStream(session *ShellContext, results *resultStream) error {
+	p := popupModel{title: "Query Results", results: results, session: session}
 	prog := tea.NewProgram(p, tea.WithAltScreen())
 	_, err := prog.Run()
 	return err
@@ -200,11 +600,18 @@ func FormatQueryResult(result interface{}, stats driver.QueryStatistics) string
 
 	sb.WriteString("📊 Results:\n\n")
 	sb.WriteString(resultStr)
+	sb.WriteString(formatStatistics(stats))
+
+	return sb.String()
+}
+
+// formatStatistics renders the statistics that end the query results.
+func formatStatistics(stats driver.QueryStatistics) string {
+	var sb strings.Builder
 	sb.WriteString("📈 Statistics:\n")
 	sb.WriteString(fmt.Sprintf("⏱️ Execution time: %v \n", stats.ExecutionTime()))
 	sb.WriteString(fmt.Sprintf("📄 Documents read: %d \n", stats.ScannedFull()))
 	sb.WriteString(fmt.Sprintf("✏️ Documents written: %d\n", stats.WritesExecuted()))
-
 	return sb.String()
 }
 
@@ -223,12 +630,13 @@ func formatBytes(bytes int) string {
 
 func formatJSONArray(arr []interface{}) string {
 	var sb strings.Builder
+	t := currentTheme()
 
 	for _, item := range arr {
 		switch v := item.(type) {
 		case map[string]interface{}:
 			jsonBytes, _ := json.MarshalIndent(v, "   ", "   ")
-			sb.WriteString("\n   " + strings.ReplaceAll(string(jsonBytes), "\n", "\n"))
+			sb.WriteString("\n   " + colorizeJSON(string(jsonBytes), t))
 		default:
 			sb.WriteString(fmt.Sprintf("%v", v))
 		}
@@ -244,5 +652,5 @@ func formatJSONObject(obj map[string]interface{}) string {
 	if err != nil {
 		return fmt.Sprintf("%v", obj)
 	}
-	return string(jsonBytes)
+	return colorizeJSON(string(jsonBytes), currentTheme())
 }